@@ -0,0 +1,167 @@
+package quiz
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultStepUp is how many right answers in a row, each given within
+	// the adaptive strategy's SlowAnswer, move up a difficulty level.
+	DefaultStepUp = 2
+	// DefaultRating is the skill rating that every user starts from, which
+	// matches problems of difficulty 1.
+	DefaultRating = 1000
+	// ratingK is the most a rating can move after a single answer
+	ratingK = 64
+)
+
+// ProblemRating places a problem on the same Elo scale as the skill
+// estimate of the adaptive strategy. Each difficulty level is 200 points
+// above the one before, and unrated problems count as difficulty 1.
+func ProblemRating(p Problem) float64 {
+	return DefaultRating + 200*float64(difficultyLevel(p)-1)
+}
+
+// Adaptive is a Strategy which follows how well the user is doing. It
+// starts with the easiest problems, moves up a difficulty level after
+// StepUp quick right answers in a row and back down after every miss.
+// Along the way it keeps an Elo rating of the user's skill. An Adaptive
+// is only good for a single run of a quiz.
+type Adaptive struct {
+	// StepUp is how many quick right answers in a row move up a level
+	StepUp int
+	// SlowAnswer is how long a right answer can take and still count
+	// towards moving up
+	SlowAnswer time.Duration
+
+	problems []Problem
+	n        int
+	rnd      *rand.Rand
+	// levels are the difficulty levels of the problems, easiest first
+	levels []int
+	level  int
+	streak int
+	asked  []bool
+	seen   int
+	rating float64
+}
+
+// NewAdaptive creates an adaptive strategy which asks n of the problems,
+// or all of them when n is zero or more than there are.
+func NewAdaptive(problems []Problem, n int, rnd *rand.Rand) *Adaptive {
+	if n <= 0 || n > len(problems) {
+		n = len(problems)
+	}
+	s := &Adaptive{
+		StepUp:     DefaultStepUp,
+		SlowAnswer: DefaultSlowAnswer,
+		problems:   problems,
+		n:          n,
+		rnd:        rnd,
+		asked:      make([]bool, len(problems)),
+		rating:     DefaultRating,
+	}
+
+	seen := make(map[int]bool)
+	for _, p := range problems {
+		if d := difficultyLevel(p); !seen[d] {
+			seen[d] = true
+			s.levels = append(s.levels, d)
+		}
+	}
+	sort.Ints(s.levels)
+	return s
+}
+
+func (s *Adaptive) Len() int {
+	return s.n
+}
+
+func (s *Adaptive) Next(answers []Answer) (Problem, bool) {
+	for _, a := range answers[s.seen:] {
+		s.update(a)
+	}
+	s.seen = len(answers)
+	if len(answers) >= s.n {
+		return Problem{}, false
+	}
+
+	// Ask a problem at the current level, or the closest level with any
+	// left, preferring the easier one
+	for dist := 0; dist < len(s.levels); dist++ {
+		for _, l := range []int{s.level - dist, s.level + dist} {
+			if l < 0 || l >= len(s.levels) {
+				continue
+			}
+			if i, ok := s.pick(s.levels[l]); ok {
+				s.asked[i] = true
+				return s.problems[i], true
+			}
+		}
+	}
+	return Problem{}, false
+}
+
+// pick chooses a random problem of the given difficulty which hasn't been
+// asked yet.
+func (s *Adaptive) pick(difficulty int) (int, bool) {
+	var left []int
+	for i, p := range s.problems {
+		if !s.asked[i] && difficultyLevel(p) == difficulty {
+			left = append(left, i)
+		}
+	}
+	if len(left) == 0 {
+		return 0, false
+	}
+	return left[s.rnd.Intn(len(left))], true
+}
+
+// update moves the level and rating after an answer.
+func (s *Adaptive) update(a Answer) {
+	// Elo with the problem as the opponent, where partial credit counts
+	// as a partial win
+	expected := 1 / (1 + math.Pow(10, (ProblemRating(a.Problem)-s.rating)/400))
+	s.rating += ratingK * (a.Credit - expected)
+
+	switch {
+	case !a.Correct || a.TimedOut:
+		s.streak = 0
+		if s.level > 0 {
+			s.level--
+		}
+	case a.Latency <= s.SlowAnswer:
+		s.streak++
+		if s.streak >= s.StepUp && s.level < len(s.levels)-1 {
+			s.streak = 0
+			s.level++
+		}
+	default:
+		// Right but slow, so stay at this level
+		s.streak = 0
+	}
+}
+
+// Rating returns the estimated skill of the user on the same scale as
+// ProblemRating.
+func (s *Adaptive) Rating() float64 {
+	return s.rating
+}
+
+// Level returns the estimated skill as a difficulty level, so 2.5 is
+// halfway between problems of difficulty 2 and 3.
+func (s *Adaptive) Level() float64 {
+	return 1 + (s.rating-DefaultRating)/200
+}
+
+// difficultyLevel returns the difficulty of a problem with unrated
+// problems counting as 1.
+func difficultyLevel(p Problem) int {
+	if p.Difficulty < 1 {
+		return 1
+	}
+	return p.Difficulty
+}
@@ -0,0 +1,139 @@
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestAdaptive(t *testing.T) {
+	// Three problems at each of three levels
+	var problems []Problem
+	for d := 1; d <= 3; d++ {
+		for i := 1; i <= 3; i++ {
+			problems = append(problems, Problem{Question: fmt.Sprintf("d%d-%d", d, i), Answer: "x", Difficulty: d})
+		}
+	}
+	quick, slow := time.Second, 2*DefaultSlowAnswer
+
+	steps := []struct {
+		// difficulty is of the problem the strategy should ask next
+		difficulty int
+		correct    bool
+		latency    time.Duration
+	}{
+		{1, true, quick},
+		// Two quick right answers in a row move up a level
+		{1, true, quick},
+		{2, true, quick},
+		{2, true, quick},
+		// A miss moves back down
+		{3, false, quick},
+		// A slow right answer stays at the level
+		{2, true, slow},
+		// Every problem of difficulty 2 has been asked, so the easier
+		// level is used
+		{1, true, quick},
+	}
+
+	s := NewAdaptive(problems, len(steps), rand.New(rand.NewSource(1)))
+	if s.Len() != len(steps) {
+		t.Fatalf("Len() = %d, want %d", s.Len(), len(steps))
+	}
+	var answers []Answer
+	asked := make(map[string]bool)
+	for i, step := range steps {
+		p, ok := s.Next(answers)
+		if !ok {
+			t.Fatalf("Next() ran out of problems at step %d", i+1)
+		}
+		if p.Difficulty != step.difficulty {
+			t.Fatalf("step %d asked %q, want difficulty %d", i+1, p.Question, step.difficulty)
+		}
+		if asked[p.Question] {
+			t.Fatalf("step %d asked %q again", i+1, p.Question)
+		}
+		asked[p.Question] = true
+
+		credit := 0.0
+		if step.correct {
+			credit = 1
+		}
+		answers = append(answers, Answer{Problem: p, Correct: step.correct, Credit: credit, Latency: step.latency})
+	}
+	if p, ok := s.Next(answers); ok {
+		t.Errorf("Next() = %q after %d problems, want none", p.Question, len(steps))
+	}
+
+	// The rating follows Elo with each problem as the opponent
+	const wantRating = 1189.0982390512402
+	if got := s.Rating(); math.Abs(got-wantRating) > 1e-9 {
+		t.Errorf("Rating() = %v, want %v", got, wantRating)
+	}
+	if got, want := s.Level(), 1+(wantRating-DefaultRating)/200; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Level() = %v, want %v", got, want)
+	}
+}
+
+func TestAdaptiveStepUp(t *testing.T) {
+	var problems []Problem
+	for d := 1; d <= 2; d++ {
+		for i := 1; i <= 4; i++ {
+			problems = append(problems, Problem{Question: fmt.Sprintf("d%d-%d", d, i), Answer: "x", Difficulty: d})
+		}
+	}
+
+	for _, stepUp := range []int{1, 2, 3} {
+		t.Run(fmt.Sprint(stepUp), func(t *testing.T) {
+			s := NewAdaptive(problems, 0, rand.New(rand.NewSource(2)))
+			s.StepUp = stepUp
+
+			// Right answers stay at difficulty 1 until StepUp of them
+			var answers []Answer
+			for i := 0; i <= stepUp; i++ {
+				p, ok := s.Next(answers)
+				if !ok {
+					t.Fatal("Next() ran out of problems")
+				}
+				want := 1
+				if i == stepUp {
+					want = 2
+				}
+				if p.Difficulty != want {
+					t.Fatalf("problem %d has difficulty %d, want %d", i+1, p.Difficulty, want)
+				}
+				answers = append(answers, Answer{Problem: p, Correct: true, Credit: 1, Latency: time.Second})
+			}
+		})
+	}
+}
+
+func TestAdaptiveSeeded(t *testing.T) {
+	// The same seed asks the same problems
+	problems := []Problem{
+		{Question: "a", Answer: "x"}, {Question: "b", Answer: "x"},
+		{Question: "c", Answer: "x"}, {Question: "d", Answer: "x"},
+	}
+	order := func() []string {
+		s := NewAdaptive(problems, 0, rand.New(rand.NewSource(42)))
+		var answers []Answer
+		var questions []string
+		for {
+			p, ok := s.Next(answers)
+			if !ok {
+				return questions
+			}
+			questions = append(questions, p.Question)
+			answers = append(answers, Answer{Problem: p})
+		}
+	}
+	first, second := order(), order()
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("the same seed asked %v and then %v", first, second)
+	}
+	if len(first) != len(problems) {
+		t.Errorf("asked %v, want all %d problems", first, len(problems))
+	}
+}
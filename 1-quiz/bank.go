@@ -0,0 +1,571 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Loader reads a problem bank.
+type Loader func(r io.Reader) ([]Problem, error)
+
+// loaders maps file extensions to the Loader used for banks with that
+// extension.
+var loaders = map[string]Loader{
+	".csv":  ReadCSV,
+	".json": ReadJSON,
+	".yaml": ReadYAML,
+	".yml":  ReadYAML,
+	".toml": ReadTOML,
+}
+
+// RegisterLoader makes LoadFile use the loader for files with the given
+// extension, replacing any loader already registered for it.
+func RegisterLoader(ext string, l Loader) {
+	loaders[strings.ToLower(ext)] = l
+}
+
+// LoaderFor returns the loader registered for the extension of path.
+func LoaderFor(path string) (Loader, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	l, ok := loaders[ext]
+	if !ok {
+		return nil, fmt.Errorf("%s: no loader for %q files", path, ext)
+	}
+	return l, nil
+}
+
+// LoadFile reads the problem bank at path using the loader registered for
+// its extension. Errors are prefixed with the path.
+func LoadFile(path string) ([]Problem, error) {
+	l, err := LoaderFor(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	problems, err := l(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return problems, nil
+}
+
+// bankProblem is a problem as it is written in a bank file, before it has
+// been validated.
+type bankProblem struct {
+	ID       string `json:"id" yaml:"id" toml:"id"`
+	Question string `json:"question" yaml:"question" toml:"question"`
+	// Answer and Answers can be used together, in which case Answer is
+	// the main answer and Answers are alternatives
+	Answer  string   `json:"answer" yaml:"answer" toml:"answer"`
+	Answers []string `json:"answers" yaml:"answers" toml:"answers"`
+	// Options make the problem multiple choice, with the answer given as
+	// option letters
+	Options     []string `json:"options" yaml:"options" toml:"options"`
+	MultiSelect bool     `json:"multi_select" yaml:"multi_select" toml:"multi_select"`
+	Hints       []string `json:"hints" yaml:"hints" toml:"hints"`
+	Tags        []string `json:"tags" yaml:"tags" toml:"tags"`
+	Difficulty  int      `json:"difficulty" yaml:"difficulty" toml:"difficulty"`
+	// TimeLimit is either a number of seconds or a duration string
+	TimeLimit   interface{} `json:"time_limit" yaml:"time_limit" toml:"time_limit"`
+	Match       string      `json:"match" yaml:"match" toml:"match"`
+	Explanation string      `json:"explanation" yaml:"explanation" toml:"explanation"`
+	Weight      float64     `json:"weight" yaml:"weight" toml:"weight"`
+}
+
+// bankFile is the layout shared by the JSON, YAML and TOML banks.
+type bankFile struct {
+	Problems []bankProblem `json:"problems" yaml:"problems" toml:"problems"`
+}
+
+// problem validates b and converts it into a Problem.
+func (b bankProblem) problem() (Problem, error) {
+	p := Problem{
+		ID:          strings.TrimSpace(b.ID),
+		Question:    strings.TrimSpace(b.Question),
+		Hints:       b.Hints,
+		Tags:        b.Tags,
+		Difficulty:  b.Difficulty,
+		Explanation: b.Explanation,
+		Weight:      b.Weight,
+	}
+	if p.Question == "" {
+		return p, errors.New("missing question")
+	}
+
+	answers := b.Answers
+	if strings.TrimSpace(b.Answer) != "" {
+		answers = append([]string{b.Answer}, answers...)
+	}
+	for _, a := range answers {
+		if strings.TrimSpace(a) == "" {
+			return p, errors.New("answers must not be empty")
+		}
+	}
+	if len(answers) == 0 {
+		return p, errors.New("missing answer")
+	}
+	p.Answer, p.Alternatives = answers[0], answers[1:]
+	for _, a := range p.Alternatives {
+		if _, sealed := parseSealed(a); sealed != p.IsSealed() {
+			return p, errors.New("answers must either all be sealed or none of them")
+		}
+	}
+
+	if len(b.Options) > 0 {
+		if err := b.choiceProblem(&p, answers); err != nil {
+			return p, err
+		}
+	} else if b.MultiSelect {
+		return p, errors.New("multi_select needs options")
+	}
+
+	if p.Difficulty < 0 {
+		return p, fmt.Errorf("difficulty %d must not be negative", p.Difficulty)
+	}
+	if p.Weight < 0 {
+		return p, fmt.Errorf("weight %v must not be negative", p.Weight)
+	}
+
+	limit, err := timeLimitValue(b.TimeLimit)
+	if err != nil {
+		return p, err
+	}
+	p.TimeLimit = limit
+
+	matcher, err := ParseMatcher(b.Match)
+	if err != nil {
+		return p, err
+	}
+	p.Matcher = matcher
+
+	return p, nil
+}
+
+// choiceProblem validates the options of a multiple choice problem and
+// stores the correct option letters in p.Answer.
+func (b bankProblem) choiceProblem(p *Problem, answers []string) error {
+	if len(b.Options) > 26 {
+		return fmt.Errorf("too many options, at most 26 are allowed, got %d", len(b.Options))
+	}
+	for i, o := range b.Options {
+		if strings.TrimSpace(o) == "" {
+			return fmt.Errorf("option %s is empty", choiceLetter(i))
+		}
+	}
+	if b.Match != "" {
+		return errors.New("match can't be used with options")
+	}
+
+	p.Options = b.Options
+	p.MultiSelect = b.MultiSelect
+	if p.IsSealed() {
+		// Sealed options are checked when the problem is answered
+		if len(answers) > 1 {
+			return errors.New("sealed options must be a single answer")
+		}
+		return nil
+	}
+
+	// Both "A,C" and ["A", "C"] are accepted for the correct options
+	correct, err := parseChoices(strings.Join(answers, ","), len(b.Options))
+	if err != nil {
+		return fmt.Errorf("invalid answer: %v", err)
+	}
+	p.Answer = formatChoices(correct)
+	p.Alternatives = nil
+	return nil
+}
+
+// buildProblems validates every record in a bank, using where to describe
+// the position of a record in error messages.
+func buildProblems(records []bankProblem, where func(i int) string) ([]Problem, error) {
+	problems := []Problem{}
+	seen := make(map[string]string)
+	for i, rec := range records {
+		p, err := rec.problem()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", where(i), err)
+		}
+		if p.ID != "" {
+			if first, ok := seen[p.ID]; ok {
+				return nil, fmt.Errorf("%s: duplicate id %q, first used at %s", where(i), p.ID, first)
+			}
+			seen[p.ID] = where(i)
+		}
+		problems = append(problems, p)
+	}
+	return problems, nil
+}
+
+// recordWhere describes records in banks which don't have line numbers
+// for each record.
+func recordWhere(i int) string {
+	return fmt.Sprintf("problem %d", i+1)
+}
+
+// csvColumns are the column names understood in the header of a csv bank.
+// Columns holding lists separate their values with csvListSeparator.
+var csvColumns = map[string]bool{
+	"id":           true,
+	"question":     true,
+	"answer":       true,
+	"answers":      true,
+	"options":      true,
+	"multi_select": true,
+	"hints":        true,
+	"tags":         true,
+	"difficulty":   true,
+	"time_limit":   true,
+	"match":        true,
+	"explanation":  true,
+	"weight":       true,
+}
+
+const csvListSeparator = "|"
+
+// ReadCSV reads problems from a csv file in the format of
+// 'question,answer[,time limit[,matcher]]'. The optional third column is
+// the time limit for that problem, either in seconds or as a duration like
+// "1m30s". The optional fourth column is a matcher spec as understood by
+// ParseMatcher.
+//
+// If the first row contains a "question" column it is treated as a header
+// instead, and the columns can be any of id, question, answer, answers,
+// options, multi_select, hints, tags, difficulty, time_limit, match,
+// explanation and weight in any order. The answers, options, hints and
+// tags columns separate their values with "|".
+func ReadCSV(r io.Reader) ([]Problem, error) {
+	records, rows, err := readCSVRecords(r, func(row int, err error) error {
+		return fmt.Errorf("line %d: %v", row, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildProblems(records, func(i int) string {
+		return fmt.Sprintf("line %d", rows[i])
+	})
+}
+
+// readCSVRecords reads the rows of a csv bank along with the line each
+// one starts on. Rows which can't be read are passed to skip, and reading
+// stops with the error it returns unless that is nil.
+func readCSVRecords(r io.Reader, skip func(row int, err error) error) ([]bankProblem, []int, error) {
+	reader := csv.NewReader(r)
+	// Rows may or may not have the optional columns
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	var records []bankProblem
+	var rows []int
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := skip(parseErr.StartLine, parseErr.Err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row, _ := reader.FieldPos(0)
+
+		if header == nil && len(records) == 0 && isCSVHeader(line) {
+			// Without the header none of the other rows can be read
+			if header, err = csvHeader(line); err != nil {
+				return nil, nil, skip(row, err)
+			}
+			continue
+		}
+
+		var rec bankProblem
+		if header != nil {
+			rec, err = csvNamedRecord(header, line)
+		} else {
+			rec, err = csvRecord(line)
+		}
+		if err != nil {
+			if err := skip(row, err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		records = append(records, rec)
+		rows = append(rows, row)
+	}
+	return records, rows, nil
+}
+
+func isCSVHeader(line []string) bool {
+	for _, col := range line {
+		if strings.EqualFold(strings.TrimSpace(col), "question") {
+			return true
+		}
+	}
+	return false
+}
+
+func csvHeader(line []string) ([]string, error) {
+	header := make([]string, len(line))
+	for i, col := range line {
+		col = strings.ToLower(strings.TrimSpace(col))
+		col = strings.ReplaceAll(col, " ", "_")
+		if !csvColumns[col] {
+			return nil, fmt.Errorf("unknown column %q", line[i])
+		}
+		header[i] = col
+	}
+	return header, nil
+}
+
+// csvRecord reads a row in the 'question,answer[,time limit[,matcher]]'
+// format.
+func csvRecord(line []string) (bankProblem, error) {
+	if len(line) < 2 {
+		return bankProblem{}, errors.New("expected 'question,answer'")
+	}
+	if len(line) > 4 {
+		return bankProblem{}, fmt.Errorf("expected at most 4 columns, got %d", len(line))
+	}
+	rec := bankProblem{Question: line[0], Answer: line[1]}
+	if len(line) > 2 && strings.TrimSpace(line[2]) != "" {
+		rec.TimeLimit = line[2]
+	}
+	if len(line) > 3 {
+		rec.Match = line[3]
+	}
+	return rec, nil
+}
+
+// csvNamedRecord reads a row from a csv bank with a header.
+func csvNamedRecord(header []string, line []string) (bankProblem, error) {
+	var rec bankProblem
+	if len(line) != len(header) {
+		return rec, fmt.Errorf("expected %d columns, got %d", len(header), len(line))
+	}
+	for i, col := range header {
+		value := line[i]
+		switch col {
+		case "id":
+			rec.ID = value
+		case "question":
+			rec.Question = value
+		case "answer":
+			rec.Answer = value
+		case "answers":
+			rec.Answers = splitList(value)
+		case "options":
+			rec.Options = splitList(value)
+		case "multi_select":
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			multi, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return rec, fmt.Errorf("invalid multi_select %q", value)
+			}
+			rec.MultiSelect = multi
+		case "hints":
+			rec.Hints = splitList(value)
+		case "tags":
+			rec.Tags = splitList(value)
+		case "difficulty":
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			difficulty, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return rec, fmt.Errorf("invalid difficulty %q", value)
+			}
+			rec.Difficulty = difficulty
+		case "time_limit":
+			if strings.TrimSpace(value) != "" {
+				rec.TimeLimit = value
+			}
+		case "match":
+			rec.Match = value
+		case "explanation":
+			rec.Explanation = value
+		case "weight":
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return rec, fmt.Errorf("invalid weight %q", value)
+			}
+			rec.Weight = weight
+		}
+	}
+	return rec, nil
+}
+
+// splitList splits a csv cell holding several values, dropping empty
+// ones.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, csvListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ReadJSON reads problems from a JSON bank in the format of
+//
+//	{"problems": [{"question": "5+5", "answer": "10"}, ...]}
+//
+// Each problem can also have the fields id, answers, options,
+// multi_select, hints, tags, difficulty, time_limit, match, explanation
+// and weight.
+func ReadJSON(r io.Reader) ([]Problem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	var bank bankFile
+	if err := d.Decode(&bank); err != nil {
+		if line := jsonErrorLine(data, err); line > 0 {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		return nil, err
+	}
+
+	return buildProblems(bank.Problems, recordWhere)
+}
+
+// jsonErrorLine returns the line of data that a decoding error is on, or
+// zero when the error doesn't say.
+func jsonErrorLine(data []byte, err error) int {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return lineAt(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return lineAt(data, typeErr.Offset)
+	}
+	return 0
+}
+
+// lineAt returns the line number of the byte offset in data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// ReadYAML reads problems from a YAML bank in the format of
+//
+//	problems:
+//	  - question: 5+5
+//	    answer: "10"
+//
+// with the same fields as ReadJSON.
+func ReadYAML(r io.Reader) ([]Problem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var bank bankFile
+	if err := yaml.UnmarshalStrict(data, &bank); err != nil {
+		return nil, err
+	}
+
+	return buildProblems(bank.Problems, recordWhere)
+}
+
+// ReadTOML reads problems from a TOML bank in the format of
+//
+//	[[problems]]
+//	question = "5+5"
+//	answer = "10"
+//
+// with the same fields as ReadJSON.
+func ReadTOML(r io.Reader) ([]Problem, error) {
+	var bank bankFile
+	md, err := toml.NewDecoder(r).Decode(&bank)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown field %q", undecoded[0].String())
+	}
+
+	return buildProblems(bank.Problems, recordWhere)
+}
+
+// timeLimitValue converts a time limit decoded from a bank into a
+// duration. Numbers are a number of seconds.
+func timeLimitValue(v interface{}) (time.Duration, error) {
+	var secs float64
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return parseTimeLimit(v)
+	case int:
+		secs = float64(v)
+	case int64:
+		secs = float64(v)
+	case float64:
+		secs = v
+	default:
+		return 0, fmt.Errorf("invalid time limit %v", v)
+	}
+	if secs < 0 {
+		return 0, fmt.Errorf("time limit %v must not be negative", v)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// parseTimeLimit parses a time limit given either as a whole number of
+// seconds or as a time.Duration string.
+func parseTimeLimit(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if secs, err := strconv.Atoi(s); err == nil {
+		if secs < 0 {
+			return 0, fmt.Errorf("time limit %q must not be negative", s)
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time limit %q", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("time limit %q must not be negative", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,179 @@
+package quiz
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCSVHeader(t *testing.T) {
+	bank := "id,question,answers,tags,difficulty,time_limit,match,explanation,weight\n" +
+		"add-1,5+5,10|ten,addition|easy,1,10,nocase,Five and five,2.5\n"
+	problems, err := ReadCSV(strings.NewReader(bank))
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("ReadCSV() = %d problems, want 1", len(problems))
+	}
+	p := problems[0]
+	if p.ID != "add-1" || p.Answer != "10" || len(p.Alternatives) != 1 || p.Difficulty != 1 ||
+		p.Explanation != "Five and five" || p.Weight != 2.5 {
+		t.Errorf("ReadCSV() = %+v", p)
+	}
+}
+
+func TestLoaders(t *testing.T) {
+	// full is the problem every format below describes
+	full := Problem{
+		ID:           "add-1",
+		Question:     "5+5",
+		Answer:       "10",
+		Alternatives: []string{"ten"},
+		Hints:        []string{"Count on your fingers"},
+		Tags:         []string{"addition"},
+		Difficulty:   2,
+		TimeLimit:    90 * time.Second,
+		Matcher:      CaseInsensitive{},
+		Explanation:  "Five and five more make ten.",
+		Weight:       2,
+	}
+	choice := Problem{Question: "Which are prime?", Options: []string{"4", "5", "7"}, Answer: "B,C"}
+
+	tests := []struct {
+		name string
+		ext  string
+		bank string
+		want []Problem
+		// err is part of the error expected, or empty when the bank
+		// should load
+		err string
+	}{
+		{name: "csv", ext: ".csv", bank: "5+5,10\npi,3.14,30,numeric:0.01\n", want: []Problem{
+			{Question: "5+5", Answer: "10", Alternatives: []string{}},
+			{Question: "pi", Answer: "3.14", Alternatives: []string{}, TimeLimit: 30 * time.Second, Matcher: Numeric{Tolerance: 0.01}},
+		}},
+		{
+			name: "csv header", ext: ".csv",
+			bank: "id,question,answers,hints,tags,difficulty,time_limit,match,explanation,weight\n" +
+				"add-1,5+5,10|ten,Count on your fingers,addition,2,1m30s,nocase,Five and five more make ten.,2\n",
+			want: []Problem{full},
+		},
+		{name: "csv options", ext: ".csv", bank: "question,options,answer\nWhich are prime?,4|5|7,\"B,C\"\n", want: []Problem{choice}},
+		{name: "csv too few columns", ext: ".csv", bank: "5+5,10\n7+3\n", err: "line 2: expected 'question,answer'"},
+		{name: "csv bad time limit", ext: ".csv", bank: "5+5,10,soon\n", err: "line 1"},
+		{name: "csv unknown column", ext: ".csv", bank: "question,answer,colour\n5+5,10,red\n", err: `unknown column "colour"`},
+		{name: "csv unknown matcher", ext: ".csv", bank: "5+5,10,,bogus\n", err: `line 1: unknown matcher "bogus"`},
+
+		{
+			name: "json", ext: ".json",
+			bank: `{"problems": [
+  {"id": "add-1", "question": "5+5", "answers": ["10", "ten"], "hints": ["Count on your fingers"], "tags": ["addition"],
+   "difficulty": 2, "time_limit": 90, "match": "nocase", "explanation": "Five and five more make ten.", "weight": 2},
+  {"question": "Which are prime?", "options": ["4", "5", "7"], "answer": "B,C"}
+]}`,
+			want: []Problem{full, choice},
+		},
+		{name: "json syntax", ext: ".json", bank: "{\"problems\": [\n  {\"question\": \"5+5\" \"answer\": \"10\"}\n]}", err: "line 2: invalid character"},
+		{name: "json wrong type", ext: ".json", bank: "{\"problems\": [\n  {\"question\": \"5+5\",\n   \"answer\": 10}\n]}", err: "line 3: json: cannot unmarshal number"},
+		{name: "json unknown field", ext: ".json", bank: `{"problems": [{"question": "5+5", "answer": "10", "colour": "red"}]}`, err: `unknown field "colour"`},
+		{name: "json missing answer", ext: ".json", bank: `{"problems": [{"question": "5+5", "answer": "10"}, {"question": "q"}]}`, err: "problem 2: missing answer"},
+		{name: "json duplicate id", ext: ".json", bank: `{"problems": [{"id": "a", "question": "1", "answer": "1"}, {"id": "a", "question": "2", "answer": "2"}]}`, err: `problem 2: duplicate id "a", first used at problem 1`},
+
+		{
+			name: "yaml", ext: ".yaml",
+			bank: `problems:
+  - id: add-1
+    question: 5+5
+    answers: ["10", ten]
+    hints: [Count on your fingers]
+    tags: [addition]
+    difficulty: 2
+    time_limit: 1m30s
+    match: nocase
+    explanation: Five and five more make ten.
+    weight: 2
+  - question: Which are prime?
+    options: ["4", "5", "7"]
+    answer: B,C
+`,
+			want: []Problem{full, choice},
+		},
+		{name: "yaml syntax", ext: ".yml", bank: "problems:\n  - question: 5+5\n    answer: [\n", err: "yaml: line 3"},
+		{name: "yaml unknown field", ext: ".yaml", bank: "problems:\n  - question: 5+5\n    answer: \"10\"\n    colour: red\n", err: "line 4: field colour not found"},
+		{name: "yaml bad option", ext: ".yaml", bank: "problems:\n  - question: q\n    options: [x, y]\n    answer: D\n", err: "problem 1: invalid answer"},
+
+		{
+			name: "toml", ext: ".toml",
+			bank: `[[problems]]
+id = "add-1"
+question = "5+5"
+answers = ["10", "ten"]
+hints = ["Count on your fingers"]
+tags = ["addition"]
+difficulty = 2
+time_limit = "1m30s"
+match = "nocase"
+explanation = "Five and five more make ten."
+weight = 2
+
+[[problems]]
+question = "Which are prime?"
+options = ["4", "5", "7"]
+answer = "B,C"
+`,
+			want: []Problem{full, choice},
+		},
+		{name: "toml syntax", ext: ".toml", bank: "[[problems]]\nquestion = \"5+5\nanswer = \"10\"\n", err: "toml: line 2"},
+		{name: "toml unknown field", ext: ".toml", bank: "[[problems]]\nquestion = \"5+5\"\nanswer = \"10\"\ncolour = \"red\"\n", err: `unknown field "problems.colour"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bank"+tt.ext)
+			if err := os.WriteFile(path, []byte(tt.bank), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadFile(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("LoadFile() error = %v, want one containing %q", err, tt.err)
+				}
+				if !strings.HasPrefix(err.Error(), path+": ") {
+					t.Errorf("LoadFile() error = %v, want it to start with the path", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFile() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoaderFor(t *testing.T) {
+	for _, path := range []string{"bank.csv", "bank.JSON", "dir/bank.yaml", "bank.yml", "bank.toml"} {
+		if _, err := LoaderFor(path); err != nil {
+			t.Errorf("LoaderFor(%q) error = %v", path, err)
+		}
+	}
+	for _, path := range []string{"bank.txt", "bank"} {
+		if _, err := LoaderFor(path); err == nil || !strings.Contains(err.Error(), "no loader for") {
+			t.Errorf("LoaderFor(%q) error = %v, want no loader", path, err)
+		}
+	}
+
+	RegisterLoader(".TXT", func(r io.Reader) ([]Problem, error) {
+		return []Problem{{Question: "5+5", Answer: "10"}}, nil
+	})
+	defer delete(loaders, ".txt")
+	if _, err := LoaderFor("bank.txt"); err != nil {
+		t.Errorf("LoaderFor() of a registered extension error = %v", err)
+	}
+}
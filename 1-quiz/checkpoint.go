@@ -0,0 +1,239 @@
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PauseCommand is typed instead of an answer to pause the quiz. The timers
+// stop while it is paused, and a checkpoint is saved when the quiz was
+// created with WithCheckpoint.
+const PauseCommand = ":pause"
+
+// Checkpoint is a quiz which was paused part way through, with everything
+// needed to carry on exactly where it left off.
+type Checkpoint struct {
+	Time time.Time `json:"time"`
+	// Order is the keys of the problems in the order they are asked
+	Order []string `json:"order"`
+	// Options is the order the options of each multiple choice problem
+	// asked so far were shown in, lined up with Order
+	Options [][]int `json:"options,omitempty"`
+	// Index is the problem which was being asked, counting from 0
+	Index int `json:"index"`
+	// ElapsedMS is the time spent on the quiz so far and ProblemElapsedMS
+	// the time spent on the current problem, both in milliseconds and not
+	// counting time spent paused
+	ElapsedMS        int64 `json:"elapsed_ms"`
+	ProblemElapsedMS int64 `json:"problem_elapsed_ms"`
+	// ProblemHints is how many hints were revealed for the current problem
+	ProblemHints int            `json:"problem_hints,omitempty"`
+	Answers      []RecordAnswer `json:"answers"`
+	// Settings is for the caller to keep whatever it needs to recreate the
+	// quiz, such as the flags it was started with
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+// LoadCheckpoint reads a checkpoint saved with Save.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the checkpoint to path.
+func (c *Checkpoint) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// WithCheckpoint calls save with a checkpoint every time the quiz is
+// paused, so that it can be carried on later with Resume.
+func WithCheckpoint(save func(c *Checkpoint) error) Option {
+	return func(q *Quiz) {
+		q.saveCheckpoint = save
+	}
+}
+
+// resumeState is where a resumed quiz carries on from.
+type resumeState struct {
+	elapsed        time.Duration
+	problemElapsed time.Duration
+	hints          int
+	answers        []Answer
+	orders         [][]int
+}
+
+// Resume creates a quiz which carries on from a checkpoint. problems must
+// include every problem in the checkpoint, and may be in any order. The
+// problems are asked in the order they were in the checkpoint whatever
+// the Strategy.
+func Resume(problems []Problem, c *Checkpoint, opts ...Option) (*Quiz, error) {
+	byKey := make(map[string]Problem, len(problems))
+	for _, p := range problems {
+		byKey[p.Key()] = p
+	}
+
+	ordered := make([]Problem, len(c.Order))
+	for i, key := range c.Order {
+		p, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("problem %q from the checkpoint is no longer in the bank", key)
+		}
+		ordered[i] = p
+	}
+	if c.Index < 0 || c.Index > len(ordered) {
+		return nil, fmt.Errorf("checkpoint is at problem %d of %d", c.Index+1, len(ordered))
+	}
+	if len(c.Options) > len(ordered) {
+		return nil, fmt.Errorf("checkpoint has options for %d of %d problems", len(c.Options), len(ordered))
+	}
+	if len(c.Answers) != c.Index {
+		return nil, fmt.Errorf("checkpoint has %d answers at problem %d", len(c.Answers), c.Index+1)
+	}
+
+	state := &resumeState{
+		elapsed:        time.Duration(c.ElapsedMS) * time.Millisecond,
+		problemElapsed: time.Duration(c.ProblemElapsedMS) * time.Millisecond,
+		hints:          c.ProblemHints,
+		orders:         c.Options,
+	}
+	for i, a := range c.Answers {
+		p, ok := byKey[a.Key()]
+		if !ok {
+			return nil, fmt.Errorf("problem %q from the checkpoint is no longer in the bank", a.Key())
+		}
+		var order []int
+		if i < len(c.Options) {
+			order = c.Options[i]
+		}
+		state.answers = append(state.answers, Answer{
+			Problem:    p,
+			Order:      order,
+			Given:      a.Given,
+			Correct:    a.Correct,
+			Credit:     a.Credit,
+			HintsUsed:  a.Hints,
+			Points:     a.Points,
+			Latency:    a.Latency(),
+			TimedOut:   a.TimedOut,
+			Keystrokes: a.Keystrokes(),
+			Pasted:     a.Pasted,
+		})
+	}
+
+	q := New(ordered, append(opts, WithStrategy(InOrder(ordered)))...)
+	q.resume = state
+	return q, nil
+}
+
+// checkpoint captures the state of a run which is paused at the problem
+// after the answers in result. It returns nil when the strategy doesn't
+// know its problems up front, as there would be no way to carry on.
+func (q *Quiz) checkpoint(orders [][]int, result Result, elapsed, problemElapsed time.Duration, hints int) *Checkpoint {
+	s, ok := q.strategy.(orderedStrategy)
+	if !ok {
+		return nil
+	}
+	problems := s.Order()
+
+	c := &Checkpoint{
+		Time:             q.clock.Now(),
+		Order:            make([]string, len(problems)),
+		Index:            len(result.Answers),
+		ElapsedMS:        elapsed.Milliseconds(),
+		ProblemElapsedMS: problemElapsed.Milliseconds(),
+		ProblemHints:     hints,
+		Answers:          make([]RecordAnswer, len(result.Answers)),
+	}
+	for j, p := range problems {
+		c.Order[j] = p.Key()
+	}
+	for _, order := range orders {
+		if order != nil {
+			c.Options = orders
+			break
+		}
+	}
+	for j, a := range result.Answers {
+		c.Answers[j] = newRecordAnswer(a)
+	}
+	return c
+}
+
+// countdown is a timer which can be paused. It also keeps track of the
+// time it has been running for, even when it has no limit.
+type countdown struct {
+	clock   Clock
+	limit   time.Duration
+	elapsed time.Duration
+	started time.Time
+	running bool
+	// C fires once the limit is reached. It is nil while the countdown is
+	// paused or when there is no limit.
+	C <-chan time.Time
+}
+
+// newCountdown starts a countdown which has already been running for
+// elapsed. A limit of zero means it never fires.
+func newCountdown(clock Clock, limit, elapsed time.Duration) *countdown {
+	c := &countdown{clock: clock, limit: limit, elapsed: elapsed}
+	c.start()
+	return c
+}
+
+func (c *countdown) start() {
+	if c.running {
+		return
+	}
+	c.running = true
+	c.started = c.clock.Now()
+	if c.limit > 0 {
+		left := c.limit - c.elapsed
+		if left < 0 {
+			left = 0
+		}
+		c.C = c.clock.After(left)
+	}
+}
+
+func (c *countdown) stop() {
+	if !c.running {
+		return
+	}
+	c.running = false
+	c.elapsed += c.clock.Now().Sub(c.started)
+	c.C = nil
+}
+
+// deadline returns when the countdown fires, or the zero time when it has
+// no limit or is paused.
+func (c *countdown) deadline() time.Time {
+	if !c.running || c.limit <= 0 {
+		return time.Time{}
+	}
+	return c.started.Add(c.limit - c.elapsed)
+}
+
+// spent returns the time the countdown has been running for.
+func (c *countdown) spent() time.Duration {
+	if c.running {
+		return c.elapsed + c.clock.Now().Sub(c.started)
+	}
+	return c.elapsed
+}
@@ -0,0 +1,142 @@
+package quiz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// eventDisplay passes on every event the quiz shows, so that a test can
+// wait for the quiz to get somewhere before moving the clock.
+type eventDisplay chan Event
+
+func (d eventDisplay) Show(e Event) {
+	d <- e
+}
+
+// waitFor returns the next event of the given kind, skipping the others.
+func (d eventDisplay) waitFor(t *testing.T, kind EventKind) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-d:
+			if e.Kind == kind {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("the quiz never showed event %d", kind)
+		}
+	}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	type settings struct {
+		Bank string `json:"bank"`
+		Seed int64  `json:"seed"`
+	}
+	want := settings{Bank: "problems.csv", Seed: 42}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := []Option{WithTimeLimit(time.Minute), WithProblemTimeLimit(20 * time.Second)}
+
+	// The first problem is answered after 3s, then the quiz is paused 4s
+	// into the second
+	clock := newFakeClock()
+	display := make(eventDisplay, 100)
+	in, typed := io.Pipe()
+	q := New(testProblems, append(opts, WithClock(clock), WithDisplay(display), WithCheckpoint(func(c *Checkpoint) error {
+		c.Settings, _ = json.Marshal(want)
+		return c.Save(path)
+	}))...)
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := q.RunContext(ctx, in, io.Discard)
+		done <- err
+	}()
+	io.WriteString(typed, "\n")
+	display.waitFor(t, EventAsk)
+	clock.Advance(3 * time.Second)
+	io.WriteString(typed, "10\n")
+	display.waitFor(t, EventAnswer)
+	display.waitFor(t, EventAsk)
+	clock.Advance(4 * time.Second)
+	io.WriteString(typed, PauseCommand+"\n")
+	if e := display.waitFor(t, EventPause); e.Message != English.PausedSaved {
+		t.Fatalf("pausing said %q, want %q", e.Message, English.PausedSaved)
+	}
+
+	// Time spent paused doesn't count
+	clock.Advance(time.Hour)
+	stop()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext returned %v, want %v", err, context.Canceled)
+	}
+
+	c, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Index != 1 || c.ElapsedMS != 7000 || c.ProblemElapsedMS != 4000 {
+		t.Errorf("checkpoint is at problem %d after %dms and %dms on it, want 1 after 7000ms and 4000ms",
+			c.Index, c.ElapsedMS, c.ProblemElapsedMS)
+	}
+	if wantOrder := []string{"5+5", "capital of France", "7+3"}; !reflect.DeepEqual(c.Order, wantOrder) {
+		t.Errorf("Order = %q, want %q", c.Order, wantOrder)
+	}
+	var got settings
+	if err := json.Unmarshal(c.Settings, &got); err != nil || got != want {
+		t.Errorf("Settings = %s, want %+v", c.Settings, want)
+	}
+
+	// The resumed quiz carries on with the time which was left
+	clock = newFakeClock()
+	display = make(eventDisplay, 100)
+	in, typed = io.Pipe()
+	q, err = Resume(testProblems, c, append(opts, WithClock(clock), WithDisplay(display))...)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	results := make(chan Result)
+	go func() {
+		result, err := q.Run(in, io.Discard)
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+		results <- result
+	}()
+	io.WriteString(typed, "\n")
+	e := display.waitFor(t, EventAsk)
+	if e.Number != 2 || e.Problem.Question != "capital of France" {
+		t.Errorf("resumed at problem %d, %q, want 2, %q", e.Number, e.Problem.Question, "capital of France")
+	}
+	if left := e.Deadline.Sub(clock.Now()); left != 53*time.Second {
+		t.Errorf("the quiz has %v left, want 53s", left)
+	}
+	if left := e.ProblemDeadline.Sub(clock.Now()); left != 16*time.Second {
+		t.Errorf("the problem has %v left, want 16s", left)
+	}
+	clock.Advance(time.Second)
+	io.WriteString(typed, "Paris\n")
+	display.waitFor(t, EventAnswer)
+	io.WriteString(typed, "10\n")
+	result := <-results
+
+	// The answers from before the pause are kept
+	if len(result.Answers) != 3 || result.Score() != 3 {
+		t.Fatalf("answers = %+v, want all 3 right", result.Answers)
+	}
+	first, second := result.Answers[0], result.Answers[1]
+	if first.Problem.Question != "5+5" || first.Given != "10" || first.Latency != 3*time.Second {
+		t.Errorf("first answer = %+v, want 10 after 3s", first)
+	}
+	if second.Latency != 5*time.Second {
+		t.Errorf("second answer took %v, want 5s counting the time before the pause", second.Latency)
+	}
+}
@@ -0,0 +1,146 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// IsMultipleChoice reports whether the problem is answered by picking from
+// its Options rather than typing an answer.
+func (p Problem) IsMultipleChoice() bool {
+	return len(p.Options) > 0
+}
+
+// IsMultiSelect reports whether more than one option can be picked.
+func (p Problem) IsMultiSelect() bool {
+	if p.MultiSelect {
+		return true
+	}
+	correct, err := parseChoices(p.Answer, len(p.Options))
+	return err == nil && len(correct) > 1
+}
+
+// choiceLetter returns the letter used for the option at index i.
+func choiceLetter(i int) string {
+	return string(rune('A' + i))
+}
+
+// parseChoices parses a list of option letters like "A,C" into option
+// indexes. The letters can be separated by commas or spaces, or not at
+// all, and are not case sensitive. n is the number of options.
+func parseChoices(s string, n int) ([]int, error) {
+	seen := make(map[int]bool)
+	var choices []int
+	for _, r := range strings.ToUpper(s) {
+		if r == ',' || r == ' ' || r == '\t' {
+			continue
+		}
+		i := int(r - 'A')
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("%q is not one of the options A-%s", r, choiceLetter(n-1))
+		}
+		if !seen[i] {
+			seen[i] = true
+			choices = append(choices, i)
+		}
+	}
+	sort.Ints(choices)
+	return choices, nil
+}
+
+// formatChoices is the inverse of parseChoices.
+func formatChoices(choices []int) string {
+	letters := make([]string, len(choices))
+	for i, c := range choices {
+		letters[i] = choiceLetter(c)
+	}
+	return strings.Join(letters, ",")
+}
+
+// choiceCredit returns how much of a multiple choice problem was answered
+// correctly. Single select problems are either right or wrong. Multi
+// select problems give partial credit: every correct option picked is
+// worth an equal share and every wrong option picked takes a share away.
+func choiceCredit(p Problem, given []int) float64 {
+	correct, err := parseChoices(p.Answer, len(p.Options))
+	if err != nil || len(correct) == 0 {
+		return 0
+	}
+	isCorrect := make(map[int]bool)
+	for _, c := range correct {
+		isCorrect[c] = true
+	}
+
+	if !p.IsMultiSelect() {
+		if len(given) == 1 && isCorrect[given[0]] {
+			return 1
+		}
+		return 0
+	}
+
+	hits := 0
+	for _, g := range given {
+		if isCorrect[g] {
+			hits++
+		} else {
+			hits--
+		}
+	}
+	if hits <= 0 {
+		return 0
+	}
+	return float64(hits) / float64(len(correct))
+}
+
+// optionOrder returns the order the options of a problem are shown in,
+// where order[i] is the index in p.Options shown with letter i. When rnd
+// is nil the options keep the order from the bank.
+func optionOrder(p Problem, rnd *rand.Rand) []int {
+	order := make([]int, len(p.Options))
+	for i := range order {
+		order[i] = i
+	}
+	if rnd != nil {
+		rnd.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	return order
+}
+
+// shownChoices returns the answer to the problem with the letters of the
+// correct options mapped through the order they were shown in. Answers
+// which aren't option letters are returned as they are.
+func shownChoices(p Problem, order []int) string {
+	if !p.IsMultipleChoice() || len(order) != len(p.Options) {
+		return p.Answer
+	}
+	correct, err := parseChoices(p.Answer, len(p.Options))
+	if err != nil {
+		return p.Answer
+	}
+	letter := make(map[int]int, len(order))
+	for i, o := range order {
+		letter[o] = i
+	}
+	shown := make([]int, len(correct))
+	for i, c := range correct {
+		shown[i] = letter[c]
+	}
+	sort.Ints(shown)
+	return formatChoices(shown)
+}
+
+// gradeChoices maps the letters the user typed back through the order the
+// options were shown in and returns the credit for them.
+func gradeChoices(p Problem, given string, order []int) float64 {
+	shown, err := parseChoices(given, len(order))
+	if err != nil {
+		return 0
+	}
+	picked := make([]int, len(shown))
+	for i, s := range shown {
+		picked[i] = order[s]
+	}
+	return choiceCredit(p, picked)
+}
@@ -0,0 +1,75 @@
+package quiz
+
+import "testing"
+
+func TestChoiceCredit(t *testing.T) {
+	multi := Problem{Question: "Pick the primes", Options: []string{"2", "4", "5", "9"}, Answer: "A,C"}
+	single := Problem{Question: "Pick the even prime", Options: []string{"2", "3", "5"}, Answer: "A"}
+	picked := Problem{Question: "Pick any primes", Options: []string{"2", "4", "9"}, Answer: "A", MultiSelect: true}
+
+	tests := []struct {
+		name    string
+		problem Problem
+		given   []int
+		want    float64
+	}{
+		{"multi all right", multi, []int{0, 2}, 1},
+		{"multi half right", multi, []int{2}, 0.5},
+		{"multi a wrong option takes a share away", multi, []int{0, 1, 2}, 0.5},
+		{"multi right and wrong cancel out", multi, []int{0, 1}, 0},
+		{"multi floor at zero", multi, []int{1, 3}, 0},
+		{"multi nothing picked", multi, nil, 0},
+		{"single right", single, []int{0}, 1},
+		{"single wrong", single, []int{1}, 0},
+		{"single with another option picked", single, []int{0, 1}, 0},
+		{"multi select with one answer", picked, []int{0}, 1},
+		{"multi select with one answer and a wrong option", picked, []int{0, 1}, 0},
+		{"invalid answer in the bank", Problem{Options: []string{"x", "y"}, Answer: "D"}, []int{0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := choiceCredit(tt.problem, tt.given); got != tt.want {
+				t.Errorf("choiceCredit(%v) = %v, want %v", tt.given, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradeChoices(t *testing.T) {
+	multi := Problem{Question: "Pick the primes", Options: []string{"2", "4", "5", "9"}, Answer: "A,C"}
+	single := Problem{Question: "Pick the even prime", Options: []string{"2", "3", "5"}, Answer: "A"}
+	inOrder := []int{0, 1, 2, 3}
+
+	tests := []struct {
+		name    string
+		problem Problem
+		given   string
+		order   []int
+		want    float64
+	}{
+		{"comma and space", multi, "a, c", inOrder, 1},
+		{"no separator", multi, "CA", inOrder, 1},
+		{"partly right", multi, "A", inOrder, 0.5},
+		{"partly right with a wrong option", multi, "A B C", inOrder, 0.5},
+		{"floor at zero", multi, "B,D", inOrder, 0},
+		{"duplicate letters count once", multi, "A,A", inOrder, 0.5},
+		{"duplicate letters of every answer", multi, "a,A,c,C", inOrder, 1},
+		{"out of range", multi, "E", inOrder, 0},
+		{"out of range with right letters", multi, "A,C,E", inOrder, 0},
+		{"not a letter", multi, "A;C", inOrder, 0},
+		{"empty", multi, "", inOrder, 0},
+		{"single", single, "a", inOrder[:3], 1},
+		{"single duplicate", single, "A,A", inOrder[:3], 1},
+		{"single out of range", single, "D", inOrder[:3], 0},
+		// The letters are those shown, so A is the option at order[0]
+		{"shuffled", multi, "A,B", []int{2, 0, 3, 1}, 1},
+		{"shuffled letters from the bank", multi, "A,C", []int{2, 0, 3, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradeChoices(tt.problem, tt.given, tt.order); got != tt.want {
+				t.Errorf("gradeChoices(%q) = %v, want %v", tt.given, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package quiz
+
+import "time"
+
+// Clock is the source of time used by the quiz. It exists so that the
+// timer and answer latencies can be controlled when the quiz is embedded
+// in other tools.
+type Clock interface {
+	Now() time.Time
+	// After sends the current time on the returned channel once the
+	// duration has elapsed, like time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock is a Clock backed by the time package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
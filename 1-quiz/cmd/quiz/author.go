@@ -0,0 +1,535 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianchong00/quiz"
+)
+
+// runAuthor edits a problem bank interactively, prompting for each field
+// of the problems which are added or changed.
+func runAuthor(args []string) {
+	fs := flag.NewFlagSet("author", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz author [bank]\n\n")
+		fmt.Fprintf(fs.Output(), "Adds, edits, moves and deletes the problems of a csv, JSON, YAML or TOML bank,\n")
+		fmt.Fprintf(fs.Output(), "which is %s unless another is given. The bank is created if it doesn't exist.\n", DefaultProblemsFile)
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := DefaultProblemsFile
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	doc, err := quiz.OpenBankDocument(path)
+	if err != nil {
+		log.Fatalf("Couldn't open the problem bank: %v", err)
+	}
+	a := &author{doc: doc, in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	if err := a.run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// author holds the state of an authoring session.
+type author struct {
+	doc *quiz.BankDocument
+	in  *bufio.Reader
+	out io.Writer
+}
+
+const authorHelp = `Commands:
+  list              list the problems
+  add               add a problem to the end of the bank
+  edit N            change problem N
+  show N            show problem N the way it is asked
+  move N M          move problem N so it is at M
+  delete N          delete problem N
+  save              write the bank
+  quit              leave, asking to save any changes
+When asked for a field, press enter to keep what is in brackets or type - to clear it.
+`
+
+func (a *author) run() error {
+	fmt.Fprintf(a.out, "Editing %s with %d problems. Type help to see the commands.\n", a.doc.Path(), a.doc.Len())
+	for {
+		line, err := a.readLine("> ")
+		if errors.Is(err, io.EOF) {
+			if a.doc.Changed() {
+				fmt.Fprintln(a.out, "Leaving without saving the changes.")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		done, err := a.command(fields[0], fields[1:])
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(a.out)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(a.out, err)
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// command runs a command typed at the prompt, reporting whether the
+// session is over.
+func (a *author) command(name string, args []string) (bool, error) {
+	nums := make([]int, len(args))
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > a.doc.Len() {
+			return false, fmt.Errorf("%q is not a problem number, expected 1 to %d", arg, a.doc.Len())
+		}
+		nums[i] = n - 1
+	}
+	wantArgs := func(n int) error {
+		if len(nums) != n {
+			return fmt.Errorf("%s takes %d problem numbers, type help to see the commands", name, n)
+		}
+		return nil
+	}
+
+	switch name {
+	case "l", "list", "ls":
+		a.list()
+	case "a", "add":
+		return false, a.add()
+	case "e", "edit":
+		if err := wantArgs(1); err != nil {
+			return false, err
+		}
+		return false, a.edit(nums[0])
+	case "s", "show", "p", "preview":
+		if err := wantArgs(1); err != nil {
+			return false, err
+		}
+		a.preview(nums[0]+1, a.doc.Problem(nums[0]))
+	case "m", "move", "mv":
+		if err := wantArgs(2); err != nil {
+			return false, err
+		}
+		return false, a.doc.Move(nums[0], nums[1])
+	case "d", "delete", "rm":
+		if err := wantArgs(1); err != nil {
+			return false, err
+		}
+		return false, a.delete(nums[0])
+	case "w", "save":
+		return false, a.save()
+	case "q", "quit", "exit":
+		if a.doc.Changed() {
+			save, err := a.confirm(fmt.Sprintf("Save the changes to %s?", a.doc.Path()), true)
+			if err != nil {
+				return false, err
+			}
+			if save {
+				if err := a.save(); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	case "h", "help", "?":
+		fmt.Fprint(a.out, authorHelp)
+	default:
+		return false, fmt.Errorf("unknown command %q, type help to see the commands", name)
+	}
+	return false, nil
+}
+
+func (a *author) list() {
+	if a.doc.Len() == 0 {
+		fmt.Fprintln(a.out, "The bank has no problems yet, type add to add one.")
+		return
+	}
+	for i, p := range a.doc.Problems() {
+		fmt.Fprintf(a.out, "%3d. %s = %s\n", i+1, shorten(p.Question, 50), shorten(strings.Join(p.AcceptedAnswers(), " | "), 20))
+	}
+}
+
+func (a *author) add() error {
+	p, ok, err := a.compose(a.doc.Len()+1, quiz.Problem{}, a.doc.Add)
+	if err != nil || !ok {
+		return err
+	}
+	fmt.Fprintf(a.out, "Added problem %d: %s\n", a.doc.Len(), shorten(p.Question, 50))
+	return nil
+}
+
+func (a *author) edit(i int) error {
+	_, ok, err := a.compose(i+1, a.doc.Problem(i), func(p quiz.Problem) error {
+		return a.doc.Set(i, p)
+	})
+	if err != nil || !ok {
+		return err
+	}
+	fmt.Fprintf(a.out, "Changed problem %d\n", i+1)
+	return nil
+}
+
+func (a *author) delete(i int) error {
+	ok, err := a.confirm(fmt.Sprintf("Delete problem %d, %q?", i+1, shorten(a.doc.Problem(i).Question, 50)), false)
+	if err != nil || !ok {
+		return err
+	}
+	return a.doc.Delete(i)
+}
+
+func (a *author) save() error {
+	if err := a.doc.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.out, "Saved %d problems to %s\n", a.doc.Len(), a.doc.Path())
+	return nil
+}
+
+// compose prompts for every field of problem n starting from p, shows
+// it and stores it with store once the user is happy with it. The user is
+// asked again when store rejects the problem.
+func (a *author) compose(n int, p quiz.Problem, store func(quiz.Problem) error) (quiz.Problem, bool, error) {
+	for {
+		var err error
+		if p, err = a.form(p); err != nil {
+			return p, false, err
+		}
+		a.preview(n, p)
+		answer, err := a.readLine("Keep this problem? [Y/n/e to edit it again] ")
+		if err != nil {
+			return p, false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "n", "no":
+			fmt.Fprintln(a.out, "Discarded")
+			return p, false, nil
+		case "e", "edit":
+			continue
+		}
+		if err := store(p); err != nil {
+			fmt.Fprintf(a.out, "The problem can't be used: %v\n", err)
+			continue
+		}
+		return p, true, nil
+	}
+}
+
+// answerTypes are the answer types offered besides matcher specs.
+const answerTypes = "text, number, choice, exact, regex or fuzzy"
+
+// form prompts for each field of a problem, offering the values of p.
+func (a *author) form(p quiz.Problem) (quiz.Problem, error) {
+	var err error
+	for {
+		if p.Question, err = a.ask("Question", p.Question); err != nil {
+			return p, err
+		}
+		if p.Question != "" {
+			break
+		}
+		fmt.Fprintln(a.out, "A problem needs a question")
+	}
+
+	kind, err := a.ask("Answer type ("+answerTypes+")", answerType(p))
+	if err != nil {
+		return p, err
+	}
+	for {
+		if kind == "" {
+			kind = "text"
+		}
+		if kind == "choice" {
+			p.Matcher = nil
+			break
+		}
+		if p.Matcher, err = parseAnswerType(kind); err == nil {
+			break
+		}
+		fmt.Fprintln(a.out, err)
+		if kind, err = a.ask("Answer type ("+answerTypes+")", answerType(p)); err != nil {
+			return p, err
+		}
+	}
+
+	if kind == "choice" {
+		// A text answer can't be kept as the correct options
+		answer := p.Answer
+		if !p.IsMultipleChoice() {
+			answer = ""
+		}
+		if p.Options, err = a.askList("Options, separated by |", p.Options, "|"); err != nil {
+			return p, err
+		}
+		if p.Answer, err = a.ask("Correct options, e.g. A or A,C", answer); err != nil {
+			return p, err
+		}
+		p.Alternatives = nil
+		if !strings.Contains(p.Answer, ",") {
+			if p.MultiSelect, err = a.confirm("Let more than one option be picked?", p.MultiSelect); err != nil {
+				return p, err
+			}
+		}
+	} else {
+		if p.Options != nil {
+			// The answer was option letters
+			p.Answer = ""
+		}
+		p.Options, p.MultiSelect = nil, false
+		if p.Answer, err = a.ask("Answer", p.Answer); err != nil {
+			return p, err
+		}
+		if p.Alternatives, err = a.askList("Other accepted answers, separated by |", p.Alternatives, "|"); err != nil {
+			return p, err
+		}
+	}
+
+	if p.Hints, err = a.askList("Hints, separated by |", p.Hints, "|"); err != nil {
+		return p, err
+	}
+	if p.Tags, err = a.askList("Tags, separated by commas", p.Tags, ","); err != nil {
+		return p, err
+	}
+	if p.Difficulty, err = a.askInt("Difficulty, 0 for unrated", p.Difficulty); err != nil {
+		return p, err
+	}
+	if p.TimeLimit, err = a.askDuration("Time limit, e.g. 30s, or none for the quiz's", p.TimeLimit); err != nil {
+		return p, err
+	}
+	if p.Weight, err = a.askFloat("Points, 0 for 1", p.Weight); err != nil {
+		return p, err
+	}
+	if p.Explanation, err = a.ask("Explanation shown when it is missed", p.Explanation); err != nil {
+		return p, err
+	}
+	if p.ID, err = a.ask("ID", p.ID); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// answerType names how the answer to p is matched for the answer type
+// prompt.
+func answerType(p quiz.Problem) string {
+	if p.IsMultipleChoice() {
+		return "choice"
+	}
+	spec, err := quiz.MatcherSpec(p.Matcher)
+	switch {
+	case err != nil, spec == "":
+		return "text"
+	case spec == "numeric":
+		return "number"
+	case spec == "fuzzy:2":
+		return "fuzzy"
+	}
+	return spec
+}
+
+// parseAnswerType returns the matcher for an answer type other than
+// choice, which is either one of the names offered or a matcher spec.
+func parseAnswerType(kind string) (quiz.Matcher, error) {
+	switch kind {
+	case "text":
+		return nil, nil
+	case "number":
+		kind = "numeric"
+	}
+	m, err := quiz.ParseMatcher(kind)
+	if err != nil {
+		return nil, fmt.Errorf("%v, expected %s", err, answerTypes)
+	}
+	return m, nil
+}
+
+// preview shows problem n the way it is asked, followed by the rest of its
+// fields.
+func (a *author) preview(n int, p quiz.Problem) {
+	fmt.Fprintf(a.out, "\nProblem #%d: %s\n", n, p.Question)
+	for i, o := range p.Options {
+		fmt.Fprintf(a.out, "  %c) %s\n", 'A'+i, o)
+	}
+	answer := p.Answer
+	if len(p.Alternatives) > 0 {
+		answer += " (also " + strings.Join(p.Alternatives, ", ") + ")"
+	}
+	fmt.Fprintf(a.out, "  Answer: %s, matched as %s\n", answer, answerType(p))
+	for i, h := range p.Hints {
+		fmt.Fprintf(a.out, "  Hint %d: %s\n", i+1, h)
+	}
+	var details []string
+	if len(p.Tags) > 0 {
+		details = append(details, "tags "+strings.Join(p.Tags, ", "))
+	}
+	if p.Difficulty > 0 {
+		details = append(details, fmt.Sprintf("difficulty %d", p.Difficulty))
+	}
+	if p.TimeLimit > 0 {
+		details = append(details, "time limit "+p.TimeLimit.String())
+	}
+	details = append(details, fmt.Sprintf("points %g", p.Worth()))
+	if p.ID != "" {
+		details = append(details, "id "+p.ID)
+	}
+	fmt.Fprintf(a.out, "  %s\n", strings.Join(details, ", "))
+	if p.Explanation != "" {
+		fmt.Fprintf(a.out, "  Explanation: %s\n", p.Explanation)
+	}
+	fmt.Fprintln(a.out)
+}
+
+// readLine prompts for a line of input, returning io.EOF when there is
+// no more.
+func (a *author) readLine(prompt string) (string, error) {
+	fmt.Fprint(a.out, prompt)
+	line, err := a.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// ask prompts for a field, keeping current when nothing is typed and
+// clearing the field when "-" is.
+func (a *author) ask(label, current string) (string, error) {
+	prompt := label + ": "
+	if current != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", label, current)
+	}
+	line, err := a.readLine(prompt)
+	if err != nil {
+		return current, err
+	}
+	switch line = strings.TrimSpace(line); line {
+	case "":
+		return current, nil
+	case "-":
+		return "", nil
+	}
+	return line, nil
+}
+
+func (a *author) askList(label string, current []string, sep string) ([]string, error) {
+	line, err := a.ask(label, strings.Join(current, sep+" "))
+	if err != nil {
+		return current, err
+	}
+	var values []string
+	for _, v := range strings.Split(line, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func (a *author) askInt(label string, current int) (int, error) {
+	for {
+		line, err := a.ask(label, strconv.Itoa(current))
+		if err != nil {
+			return current, err
+		}
+		if line == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(line)
+		if err == nil && n >= 0 {
+			return n, nil
+		}
+		fmt.Fprintf(a.out, "%q is not a whole number\n", line)
+	}
+}
+
+func (a *author) askFloat(label string, current float64) (float64, error) {
+	for {
+		line, err := a.ask(label, strconv.FormatFloat(current, 'g', -1, 64))
+		if err != nil {
+			return current, err
+		}
+		if line == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(line, 64)
+		if err == nil && f >= 0 {
+			return f, nil
+		}
+		fmt.Fprintf(a.out, "%q is not a number\n", line)
+	}
+}
+
+func (a *author) askDuration(label string, current time.Duration) (time.Duration, error) {
+	shown := "none"
+	if current > 0 {
+		shown = current.String()
+	}
+	for {
+		line, err := a.ask(label, shown)
+		if err != nil {
+			return current, err
+		}
+		if line == "" || line == "none" {
+			return 0, nil
+		}
+		// Like in a bank, a plain number is a number of seconds
+		if secs, err := strconv.Atoi(line); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, nil
+		}
+		if d, err := time.ParseDuration(line); err == nil && d >= 0 {
+			return d, nil
+		}
+		fmt.Fprintf(a.out, "%q is not a time limit, e.g. 30s or 1m30s\n", line)
+	}
+}
+
+// confirm asks a yes or no question, with def being the answer when
+// nothing is typed.
+func (a *author) confirm(question string, def bool) (bool, error) {
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+	for {
+		line, err := a.readLine(question + " " + choices + " ")
+		if err != nil {
+			return def, err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// shorten cuts s down to at most n runes for listing.
+func shorten(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
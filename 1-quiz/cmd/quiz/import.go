@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/julianchong00/quiz"
+)
+
+// delimiterNames are the names which can be given for delimiters, since
+// most of them are awkward to type.
+var delimiterNames = map[string]string{
+	"tab":       "\t",
+	"newline":   "\n",
+	"comma":     ",",
+	"semicolon": ";",
+	"space":     " ",
+	"pipe":      "|",
+	"colon":     ":",
+}
+
+func delimiter(s string) string {
+	if d, ok := delimiterNames[strings.ToLower(s)]; ok {
+		return d
+	}
+	return s
+}
+
+// runImport converts flashcards exported from another tool into problems
+// and adds them to a bank.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	from := fs.String("from", "anki", "the tool the cards were exported from, one of "+strings.Join(quiz.ImportFormats(), ", "))
+	out := fs.String("out", DefaultProblemsFile, "the bank the problems are added to, which is created if it doesn't exist")
+	fieldDelimiter := fs.String("delimiter", "", "what separates the front and back of a card: tab, comma, semicolon or any text (default tab, or what an Anki export says)")
+	cardDelimiter := fs.String("card-delimiter", "", "what separates cards in a Quizlet export: newline, semicolon or any text (default newline)")
+	keepHTML := fs.Bool("keep-html", false, "keep HTML in the cards instead of reducing them to plain text")
+	tags := fs.String("tags", "", "comma separated tags added to every imported problem")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without changing the bank")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz import [flags] export\n\nThe export can be - to read it from stdin.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	importer, err := quiz.ImporterFor(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := quiz.ImportOptions{
+		FieldDelimiter: delimiter(*fieldDelimiter),
+		CardDelimiter:  delimiter(*cardDelimiter),
+		KeepHTML:       *keepHTML,
+	}
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			opts.Tags = append(opts.Tags, tag)
+		}
+	}
+
+	path := fs.Arg(0)
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	} else {
+		path = "stdin"
+	}
+	problems, issues, err := importer(r, opts)
+	if err != nil {
+		log.Fatalf("Couldn't import %s: %v", path, err)
+	}
+
+	doc, err := quiz.OpenBankDocument(*out)
+	if err != nil {
+		log.Fatalf("Couldn't open the problem bank: %v", err)
+	}
+	// Cards which are already in the bank are left out, so that an export
+	// can be imported again after cards are added to it
+	questions := make(map[string]bool)
+	for _, p := range doc.Problems() {
+		questions[strings.ToLower(p.Question)] = true
+	}
+	var added []quiz.Problem
+	for _, p := range problems {
+		if questions[strings.ToLower(p.Question)] {
+			issues = append(issues, quiz.Issue{Message: fmt.Sprintf("skipped %q, which is already in %s", p.Question, *out)})
+			continue
+		}
+		if err := doc.Add(p); err != nil {
+			issues = append(issues, quiz.Issue{Message: fmt.Sprintf("skipped %q: %v", p.Question, err)})
+			continue
+		}
+		questions[strings.ToLower(p.Question)] = true
+		added = append(added, p)
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue.Format(path))
+	}
+	if *dryRun {
+		for _, p := range added {
+			line := fmt.Sprintf("  %s = %s", p.Question, p.Answer)
+			if len(p.Tags) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(p.Tags, ", "))
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("Would add %d problems to %s, with %d warnings\n", len(added), *out, len(issues))
+		return
+	}
+	if len(added) > 0 {
+		if err := doc.Save(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("Added %d problems to %s, with %d warnings\n", len(added), *out, len(issues))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/julianchong00/quiz"
+)
+
+// DefaultManifestFile is where quiz manifest writes the manifest unless
+// -out is given.
+const DefaultManifestFile = "quiz-manifest.json"
+
+// DefaultManifestKeyFile is where quiz keygen writes the manifest key
+// unless -out is given.
+const DefaultManifestKeyFile = "quiz-manifest.key"
+
+// runKeygen makes the key pair manifests are signed and checked with.
+func runKeygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", DefaultManifestKeyFile, "the file to write the private key to, with the public key in the same file ending .pub")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz keygen [flags]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if _, err := os.Stat(*out); err == nil {
+		log.Fatalf("%s already exists, remove it first to make a new key", *out)
+	}
+	if err := quiz.GenerateManifestKey(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote the private key to %s and the public key to %s.pub\n", *out, *out)
+}
+
+// runManifest lists banks in a signed manifest, which the quiz can be
+// told to check its bank against with -manifest.
+func runManifest(args []string) {
+	fs := flag.NewFlagSet("manifest", flag.ExitOnError)
+	keyFile := fs.String("key", "", "the file holding the private key made by quiz keygen the manifest is signed with")
+	out := fs.String("out", DefaultManifestFile, "the file to write the manifest to")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz manifest -key file [flags] bank...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *keyFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	key, err := quiz.LoadManifestKey(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	manifest, err := quiz.NewManifest(key, fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := manifest.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Signed %d banks in %s\n", len(manifest.Files), *out)
+}
+
+// runVerify checks reports signed with -sign-key haven't been changed, and
+// exits with a non-zero status when any of them have.
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyFile := fs.String("key", "", "the file holding the key the reports were signed with")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz verify -key file report...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *keyFile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	key, err := quiz.LoadKey(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := 0
+	for _, path := range fs.Args() {
+		if err := quiz.VerifyFile(key, path); err != nil {
+			fmt.Println(err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: OK\n", path)
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d reports failed verification\n", failed, fs.NArg())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/julianchong00/quiz"
+)
+
+// runLint checks problem banks and exits with a non-zero status when any
+// of them has issues, so that it can be used in CI.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	match := fs.String("match", DefaultMatcher, "the matcher the banks are asked with, for problems without their own")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz lint [flags] bank...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{DefaultProblemsFile}
+	}
+	matcher, err := quiz.ParseMatcher(*match)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	issues, failed := 0, 0
+	for _, path := range files {
+		found, err := quiz.LintFile(path, matcher)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			failed++
+			continue
+		}
+		for _, issue := range found {
+			fmt.Println(issue.Format(path))
+		}
+		issues += len(found)
+		if len(found) > 0 {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d issues in %d of %d banks\n", issues, failed, len(files))
+		os.Exit(1)
+	}
+}
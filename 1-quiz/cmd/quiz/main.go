@@ -0,0 +1,831 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianchong00/quiz"
+)
+
+const (
+	// DefaultTimeLimit is the default time limit for the quiz in seconds
+	DefaultTimeLimit = 30
+	// DefaultProblemTimeLimit is the default time limit for each problem in
+	// seconds, where 0 means problems have no deadline of their own
+	DefaultProblemTimeLimit = 0
+	DefaultProblemsFile     = "problems.csv"
+	DefaultShuffle          = false
+	DefaultShuffleOptions   = false
+	DefaultMatcher          = "nocase"
+	DefaultNormalize        = "nfc"
+	DefaultDirection        = "forward"
+	DefaultReverseMatcher   = "nocase"
+	DefaultGenerate         = ""
+	DefaultSeed             = 0
+	DefaultHintPenalty      = quiz.DefaultHintPenalty
+	DefaultTags             = ""
+	DefaultExcludeTags      = ""
+	DefaultDifficulty       = ""
+	DefaultCount            = 0
+	// DefaultAdaptiveCount is how many problems the adaptive mode asks
+	// when -n isn't given
+	DefaultAdaptiveCount = 10
+)
+
+type Config struct {
+	// TimeLimit is the time limit for the quiz in seconds
+	TimeLimit int
+	// ProblemTimeLimit is the time limit for each problem in seconds
+	ProblemTimeLimit int
+	ProblemsFile     string
+	Shuffle          bool
+	// ShuffleOptions shuffles the options of multiple choice problems
+	ShuffleOptions bool
+	// Matcher is the spec of the matcher used for problems which don't
+	// set their own
+	Matcher string
+	// Normalize is a comma separated list of the normalizations applied
+	// to answers before they are matched
+	Normalize string
+	// Direction is whether problems are asked forward, in reverse or both
+	Direction string
+	// ReverseMatcher is the spec of the matcher used for problems asked
+	// in reverse
+	ReverseMatcher string
+	// PassphraseFile holds the passphrase which opens the encrypted
+	// answers of a sealed bank
+	PassphraseFile string
+	// Generate is the spec for generated arithmetic problems, which are
+	// used instead of the problem bank when it is set
+	Generate string
+	// Seed seeds the random number generator, or 0 to use the time
+	Seed int64
+	// Tags and ExcludeTags are comma separated lists of tags to pick
+	// problems with and without
+	Tags        string
+	ExcludeTags string
+	// Difficulty is a level or range of levels, e.g. "2" or "1-3"
+	Difficulty string
+	// Count is how many problems to sample, or 0 for all of them
+	Count int
+	// HintPenalty is the fraction of a problem's points lost for each hint
+	HintPenalty float64
+	// Practice shows whether each answer was right as soon as it is given
+	Practice bool
+	// Adaptive picks each problem based on how the previous ones went
+	Adaptive bool
+	// Report is the format of the report written after the quiz, or empty
+	// for no report
+	Report string
+	// Out is the file the report is written to, or empty for stdout
+	Out string
+	// Manifest is a signed manifest the bank has to be listed in, so the
+	// quiz can't be run with a bank which has been changed
+	Manifest string
+	// ManifestKeyFile holds the public key the manifest is checked with
+	ManifestKeyFile string
+	// SignKeyFile holds the key the report is signed with. It is kept
+	// apart from the manifest key so that holding it doesn't allow signing
+	// manifests.
+	SignKeyFile string
+	// HistoryFile is where finished runs are recorded, or empty to not
+	// record them
+	HistoryFile string
+	User        string
+	// Review asks the problems which are due for review instead of the
+	// whole bank
+	Review     bool
+	ReviewFile string
+	// CheckpointFile is where the quiz is saved when it is paused
+	CheckpointFile string
+	// ResumeFile is a checkpoint to carry on from instead of starting a
+	// new quiz
+	ResumeFile string `json:"-"`
+	// TUI shows the quiz full screen when running in a terminal
+	TUI bool `json:"-"`
+	// Lang is the locale the quiz is shown in
+	Lang string `json:"-"`
+}
+
+func readProblems(bank string) []quiz.Problem {
+	// The bank can be a file, a directory, a glob, a URL or stdin
+	problems, err := quiz.SourceFor(bank).Load()
+	if err != nil {
+		log.Fatalf("Couldn't read the problem bank: %v", err)
+	}
+	return problems
+}
+
+// readSignedBank reads the bank after checking it is listed unchanged in
+// the manifest. The bank is loaded from the data which was checked, so it
+// can't be swapped for another in between.
+func (config *Config) readSignedBank() []quiz.Problem {
+	manifest, err := quiz.LoadManifest(config.Manifest)
+	if err != nil {
+		log.Fatalf("Couldn't read the manifest: %v", err)
+	}
+	if config.ManifestKeyFile == "" {
+		log.Fatal("-manifest needs -manifest-key to check its signature")
+	}
+	key, err := quiz.LoadManifestPublicKey(config.ManifestKeyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := manifest.Verify(key); err != nil {
+		log.Fatal(err)
+	}
+	if _, ok := quiz.SourceFor(config.ProblemsFile).(quiz.FileSource); !ok {
+		log.Fatal("-manifest needs -bank to be a single file")
+	}
+	data, err := os.ReadFile(config.ProblemsFile)
+	if err != nil {
+		log.Fatalf("Couldn't read the problem bank: %v", err)
+	}
+	if err := manifest.Check(config.ProblemsFile, data); err != nil {
+		log.Fatalf("Refusing to run the quiz: %v", err)
+	}
+	problems, err := quiz.ReaderSource{Name: config.ProblemsFile, R: bytes.NewReader(data)}.Load()
+	if err != nil {
+		log.Fatalf("Couldn't read the problem bank: %v", err)
+	}
+	return problems
+}
+
+func generateProblems(spec string, rnd *rand.Rand) []quiz.Problem {
+	genSpec, err := quiz.ParseGeneratorSpec(spec)
+	if err != nil {
+		log.Fatalf("Invalid generator spec: %v", err)
+	}
+	problems, err := quiz.Generate(genSpec, rnd)
+	if err != nil {
+		log.Fatalf("Couldn't generate problems: %v", err)
+	}
+	return problems
+}
+
+func main() {
+	// Subcommands are given as the first argument, otherwise run the quiz
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "author":
+			runAuthor(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "seal":
+			runSeal(os.Args[2:])
+			return
+		case "keygen":
+			runKeygen(os.Args[2:])
+			return
+		case "manifest":
+			runManifest(os.Args[2:])
+			return
+		case "verify":
+			runVerify(os.Args[2:])
+			return
+		}
+	}
+	runQuiz()
+}
+
+// defaultConfig returns the configuration used before flags are parsed.
+func defaultConfig() Config {
+	return Config{
+		TimeLimit:        DefaultTimeLimit,
+		ProblemTimeLimit: DefaultProblemTimeLimit,
+		ProblemsFile:     DefaultProblemsFile,
+		Shuffle:          DefaultShuffle,
+		ShuffleOptions:   DefaultShuffleOptions,
+		Matcher:          DefaultMatcher,
+		Normalize:        DefaultNormalize,
+		Direction:        DefaultDirection,
+		ReverseMatcher:   DefaultReverseMatcher,
+		Generate:         DefaultGenerate,
+		Seed:             DefaultSeed,
+		Tags:             DefaultTags,
+		ExcludeTags:      DefaultExcludeTags,
+		Difficulty:       DefaultDifficulty,
+		Count:            DefaultCount,
+		HintPenalty:      DefaultHintPenalty,
+		HistoryFile:      defaultHistoryFile(),
+		User:             defaultUser(),
+		ReviewFile:       defaultReviewFile(),
+		CheckpointFile:   defaultCheckpointFile(),
+		Lang:             defaultLang(),
+	}
+}
+
+// defaultLang is the locale of the user as set in the environment, in the
+// order the C library looks for it.
+func defaultLang() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := os.Getenv(env); lang != "" {
+			return lang
+		}
+	}
+	return "en"
+}
+
+// messages returns the messages for -lang. A locale from the environment
+// which the quiz hasn't been translated into falls back to English.
+func (config *Config) messages() quiz.Messages {
+	m, err := quiz.MessagesFor(config.Lang)
+	if err != nil && config.Lang != defaultLang() {
+		log.Fatal(err)
+	}
+	return m
+}
+
+// defaultCheckpointFile is where a paused quiz is saved unless -checkpoint
+// is given.
+func defaultCheckpointFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "quiz_checkpoint.json"
+	}
+	return filepath.Join(home, ".quiz", "checkpoint.json")
+}
+
+// addQuizFlags adds the flags which choose the problems and how they are
+// asked, which are shared by every command that runs a quiz.
+func (config *Config) addQuizFlags(fs *flag.FlagSet) {
+	fs.IntVar(
+		&config.TimeLimit,
+		"limit",
+		DefaultTimeLimit,
+		"the time limit for the quiz in seconds, or 0 for no limit",
+	)
+	fs.IntVar(
+		&config.ProblemTimeLimit,
+		"problem-limit",
+		DefaultProblemTimeLimit,
+		"the time limit for each problem in seconds, or 0 for no limit",
+	)
+	fs.StringVar(
+		&config.ProblemsFile,
+		"bank",
+		DefaultProblemsFile,
+		"a csv, json, yaml or toml problem bank, a directory or glob of them, an http(s) URL or - for stdin",
+	)
+	fs.StringVar(
+		&config.ProblemsFile,
+		"csv",
+		DefaultProblemsFile,
+		"a csv file in the format of 'question,answer[,time limit[,matcher]]' (same as -bank)",
+	)
+	fs.StringVar(
+		&config.Matcher,
+		"match",
+		DefaultMatcher,
+		"how answers are checked: exact, nocase, numeric[:tolerance], regex, anyof[:delimiter] or fuzzy[:distance]",
+	)
+	fs.StringVar(
+		&config.Normalize,
+		"normalize",
+		DefaultNormalize,
+		"how answers are normalized before they are checked, any of nfc, nfkc, accents and width, or none",
+	)
+	fs.StringVar(
+		&config.Direction,
+		"direction",
+		DefaultDirection,
+		"ask problems forward, in reverse with the answer shown and the question expected, or both",
+	)
+	fs.StringVar(
+		&config.ReverseMatcher,
+		"reverse-match",
+		DefaultReverseMatcher,
+		"how answers to problems asked in reverse are checked, as with -match",
+	)
+	fs.StringVar(
+		&config.PassphraseFile,
+		"passphrase-file",
+		"",
+		"the file holding the passphrase of a bank sealed with quiz seal",
+	)
+	fs.BoolVar(&config.Shuffle, "shuffle", DefaultShuffle, "shuffle the problems")
+	fs.BoolVar(
+		&config.ShuffleOptions,
+		"shuffle-options",
+		DefaultShuffleOptions,
+		"shuffle the options of multiple choice problems",
+	)
+	fs.StringVar(
+		&config.Generate,
+		"generate",
+		DefaultGenerate,
+		"generate arithmetic problems instead of reading a bank, e.g. 'ops=+-*/,min=1,max=12,count=20,negatives,decimals=1'",
+	)
+	fs.Int64Var(
+		&config.Seed,
+		"seed",
+		DefaultSeed,
+		"seed for shuffling and generating problems, or 0 to use the current time",
+	)
+	fs.StringVar(&config.Tags, "tags", DefaultTags, "only ask problems with any of these comma separated tags")
+	fs.StringVar(
+		&config.ExcludeTags,
+		"exclude-tags",
+		DefaultExcludeTags,
+		"don't ask problems with any of these comma separated tags",
+	)
+	fs.StringVar(
+		&config.Difficulty,
+		"difficulty",
+		DefaultDifficulty,
+		"only ask problems of this difficulty or range of difficulties, e.g. '2', '1-3' or '3-'",
+	)
+	fs.IntVar(
+		&config.Count,
+		"n",
+		DefaultCount,
+		"ask this many problems drawn evenly across the tags, or 0 for all of them",
+	)
+	fs.Float64Var(
+		&config.HintPenalty,
+		"hint-penalty",
+		DefaultHintPenalty,
+		"the fraction of a problem's points lost for each hint revealed with "+quiz.HintCommand,
+	)
+}
+
+// bankName identifies the problems being asked in the history and review
+// schedule.
+func (config *Config) bankName() string {
+	if config.Generate != "" {
+		return "generate:" + config.Generate
+	}
+	return config.ProblemsFile
+}
+
+// newRand returns a random number generator seeded with -seed, or with the
+// current time when no seed was given. The seed is kept in the config so
+// that a checkpoint can generate the same problems again.
+func (config *Config) newRand() *rand.Rand {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(config.Seed))
+}
+
+// loadBank generates or reads the problems which match the selection
+// flags.
+func (config *Config) loadBank(rnd *rand.Rand) []quiz.Problem {
+	var problems []quiz.Problem
+	if config.Generate != "" {
+		problems = generateProblems(config.Generate, rnd)
+	} else {
+		// Read the problems from the problem bank
+		if config.Manifest != "" {
+			problems = config.readSignedBank()
+		} else {
+			problems = readProblems(config.ProblemsFile)
+		}
+		if len(problems) == 0 {
+			log.Fatalf("The problem bank %s is empty", config.ProblemsFile)
+		}
+		if err := quiz.CheckPassphrase(problems, readPassphrase(config.PassphraseFile)); err != nil {
+			if errors.Is(err, quiz.ErrNoPassphrase) {
+				log.Fatalf("%v, give it with -passphrase-file", err)
+			}
+			log.Fatal(err)
+		}
+	}
+
+	filter, err := config.filter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	problems = filter.Apply(problems)
+	if len(problems) == 0 {
+		log.Fatal("No problems match -tags, -exclude-tags and -difficulty")
+	}
+
+	direction, err := quiz.ParseDirection(config.Direction)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Directed only knows the matchers of the problems themselves, so an
+	// anyof -match would show every problem's list of answers reversed
+	if matcher, _ := quiz.ParseMatcher(config.Matcher); direction != quiz.Forward {
+		if _, ok := matcher.(quiz.AnyOf); ok {
+			log.Fatalf("-direction %s can't be used with -match %s, give the other answers as alternatives instead", direction, config.Matcher)
+		}
+	}
+	return quiz.Directed(problems, direction)
+}
+
+// pickProblems samples -n of the problems. The problems passed in are left
+// as they are.
+func (config *Config) pickProblems(problems []quiz.Problem, rnd *rand.Rand) []quiz.Problem {
+	filter, err := config.filter()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if config.Count < 0 {
+		log.Fatalf("-n %d must not be negative", config.Count)
+	}
+	return append([]quiz.Problem(nil), quiz.Sample(problems, config.Count, filter.Tags, rnd)...)
+}
+
+// orderOptions asks the problems in a random order when -shuffle is given,
+// and otherwise leaves them in the order they are in.
+func (config *Config) orderOptions(problems []quiz.Problem, rnd *rand.Rand) []quiz.Option {
+	if !config.Shuffle {
+		return nil
+	}
+	return []quiz.Option{quiz.WithStrategy(quiz.Shuffled(problems, rnd))}
+}
+
+// filter turns the selection flags into a quiz.Filter.
+func (config *Config) filter() (quiz.Filter, error) {
+	min, max, err := quiz.ParseDifficulty(config.Difficulty)
+	if err != nil {
+		return quiz.Filter{}, err
+	}
+	return quiz.Filter{
+		Tags:          quiz.ParseTags(config.Tags),
+		ExcludeTags:   quiz.ParseTags(config.ExcludeTags),
+		MinDifficulty: min,
+		MaxDifficulty: max,
+	}, nil
+}
+
+// quizOptions turns the configuration into options for quiz.New.
+func (config *Config) quizOptions(rnd *rand.Rand) []quiz.Option {
+	matcher, err := quiz.ParseMatcher(config.Matcher)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if matcher == nil {
+		matcher = quiz.DefaultMatcher
+	}
+	reverseMatcher, err := quiz.ParseMatcher(config.ReverseMatcher)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if reverseMatcher == nil {
+		reverseMatcher = quiz.DefaultReverseMatcher
+	}
+	if config.HintPenalty < 0 {
+		log.Fatalf("-hint-penalty %v must not be negative", config.HintPenalty)
+	}
+	normalization, err := quiz.ParseNormalization(config.Normalize)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []quiz.Option{
+		quiz.WithTimeLimit(time.Duration(config.TimeLimit) * time.Second),
+		quiz.WithProblemTimeLimit(time.Duration(config.ProblemTimeLimit) * time.Second),
+		quiz.WithMatcher(matcher),
+		quiz.WithReverseMatcher(reverseMatcher),
+		quiz.WithHintPenalty(config.HintPenalty),
+		quiz.WithNormalization(normalization),
+	}
+	if config.ShuffleOptions {
+		opts = append(opts, quiz.WithShuffledOptions(rnd))
+	}
+	if config.PassphraseFile != "" {
+		opts = append(opts, quiz.WithPassphrase(readPassphrase(config.PassphraseFile)))
+	}
+	return opts
+}
+
+func runQuiz() {
+	// Set the default configuration
+	config := defaultConfig()
+
+	// Parse the command line flags
+	config.addQuizFlags(flag.CommandLine)
+	flag.StringVar(
+		&config.HistoryFile,
+		"history",
+		config.HistoryFile,
+		"the file to record results in, or empty to not record them",
+	)
+	flag.StringVar(&config.User, "user", config.User, "the name to record results under")
+	flag.BoolVar(
+		&config.Review,
+		"review",
+		false,
+		"only ask the problems which are due for review, using spaced repetition",
+	)
+	flag.StringVar(
+		&config.ReviewFile,
+		"review-file",
+		config.ReviewFile,
+		"the file the review schedule is kept in",
+	)
+	flag.StringVar(
+		&config.CheckpointFile,
+		"checkpoint",
+		config.CheckpointFile,
+		"the file the quiz is saved in when it is paused with "+quiz.PauseCommand,
+	)
+	flag.BoolVar(
+		&config.Practice,
+		"practice",
+		false,
+		"say whether each answer was right straight away, with explanations for missed problems",
+	)
+	flag.StringVar(
+		&config.Report,
+		"report",
+		"",
+		"write a report of the quiz in this format: "+strings.Join(quiz.ReportFormats(), ", "),
+	)
+	flag.StringVar(&config.Out, "out", "", "the file to write the report to instead of stdout")
+	flag.StringVar(
+		&config.Manifest,
+		"manifest",
+		"",
+		"only run the quiz if the bank is listed in this manifest made by quiz manifest, unchanged",
+	)
+	flag.StringVar(
+		&config.ManifestKeyFile,
+		"manifest-key",
+		"",
+		"the file holding the public key made by quiz keygen which -manifest is checked with",
+	)
+	flag.StringVar(
+		&config.SignKeyFile,
+		"sign-key",
+		"",
+		"the file holding the key the report is signed with, in -out.sig",
+	)
+	flag.BoolVar(
+		&config.Adaptive,
+		"adaptive",
+		false,
+		fmt.Sprintf(
+			"pick harder or easier problems depending on how it's going and estimate your skill, asking -n problems (default %d)",
+			DefaultAdaptiveCount,
+		),
+	)
+	flag.StringVar(&config.ResumeFile, "resume", "", "carry on a paused quiz from its checkpoint file")
+	flag.BoolVar(
+		&config.TUI,
+		"tui",
+		false,
+		"show the quiz full screen with a live countdown, when running in a terminal",
+	)
+	flag.StringVar(
+		&config.Lang,
+		"lang",
+		config.Lang,
+		"the language to show the quiz in, one of "+strings.Join(quiz.Locales(), ", "),
+	)
+	flag.Parse()
+	messages := config.messages()
+	if config.Report != "" {
+		if _, err := quiz.ReportWriterFor(config.Report); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if config.SignKeyFile != "" && config.Report != "" && config.Out == "" {
+		log.Fatal("-sign-key signs the report, which needs -out to write it to a file")
+	}
+
+	// A resumed quiz is asked with the same settings it was started with
+	var checkpoint *quiz.Checkpoint
+	if config.ResumeFile != "" {
+		var err error
+		checkpoint, err = quiz.LoadCheckpoint(config.ResumeFile)
+		if err != nil {
+			log.Fatalf("Couldn't read the checkpoint: %v", err)
+		}
+		if err := json.Unmarshal(checkpoint.Settings, &config); err != nil {
+			log.Fatalf("Couldn't read the checkpoint: %v", err)
+		}
+	}
+
+	rnd := config.newRand()
+	problems := config.loadBank(rnd)
+	bank := config.bankName()
+
+	var scheduler *quiz.Scheduler
+	if config.Review {
+		var err error
+		scheduler, err = quiz.LoadScheduler(config.ReviewFile, quiz.RealClock{})
+		if err != nil {
+			log.Fatalf("Couldn't read the review schedule: %v", err)
+		}
+	}
+	if scheduler != nil && checkpoint == nil {
+		due := scheduler.Select(bank, problems, 0)
+		if len(due) == 0 {
+			next, _ := scheduler.NextDue(bank, problems)
+			fmt.Printf(messages.NothingToReview+"\n", next.Local().Format("2006-01-02 15:04"))
+			return
+		}
+		problems = due
+	}
+
+	// -n samples the problems left after review, while the adaptive mode
+	// picks its own as it goes. A resumed quiz finds the problems it was
+	// asking in the whole bank.
+	if !config.Adaptive && checkpoint == nil {
+		problems = config.pickProblems(problems, rnd)
+	}
+
+	// Pausing saves the quiz along with the settings needed to ask the
+	// same problems again
+	paused := false
+	opts := append(config.quizOptions(rnd), quiz.WithCheckpoint(func(c *quiz.Checkpoint) error {
+		settings, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		c.Settings = settings
+		if err := c.Save(config.CheckpointFile); err != nil {
+			return err
+		}
+		paused = true
+		return nil
+	}))
+	// The full screen UI draws its countdowns by the same clock as the quiz
+	var clock quiz.Clock = quiz.RealClock{}
+	opts = append(opts, quiz.WithMessages(messages), quiz.WithClock(clock))
+	if config.Practice {
+		opts = append(opts, quiz.WithPractice())
+	}
+	// Without a terminal the full screen UI falls back to plain lines
+	var ui *tui
+	if config.TUI && canUseTUI() {
+		ui = newTUI(clock, config.Practice, messages)
+		opts = append(opts, quiz.WithDisplay(ui))
+	}
+	var adaptive *quiz.Adaptive
+	if config.Adaptive {
+		n := config.Count
+		if n == 0 {
+			n = DefaultAdaptiveCount
+		}
+		adaptive = quiz.NewAdaptive(problems, n, rnd)
+		opts = append(opts, quiz.WithStrategy(adaptive))
+	} else {
+		opts = append(opts, config.orderOptions(problems, rnd)...)
+	}
+
+	var q *quiz.Quiz
+	if checkpoint != nil {
+		var err error
+		q, err = quiz.Resume(problems, checkpoint, opts...)
+		if err != nil {
+			log.Fatalf("Couldn't resume the quiz: %v", err)
+		}
+		fmt.Printf(messages.CarryingOn+"\n", checkpoint.Index+1, len(q.Problems()))
+	} else {
+		q = quiz.New(problems, opts...)
+	}
+
+	// Ctrl+C ends the quiz early but still shows the score so far. A
+	// second one kills it straight away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// A bank read from stdin leaves the answers to be typed in the
+	// terminal
+	var in io.Reader = os.Stdin
+	if config.ProblemsFile == "-" && config.Generate == "" {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			log.Fatalf("Reading the bank from stdin needs a terminal to answer in: %v", err)
+		}
+		defer tty.Close()
+		in = tty
+	}
+
+	// The full screen UI reads the keys itself, so it stops the quiz on
+	// Ctrl+C instead of the signal
+	if ui != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		if err := ui.open(cancel); err != nil {
+			log.Fatalf("Couldn't start the full screen UI: %v", err)
+		}
+		in = ui
+	}
+
+	result, err := q.RunContext(ctx, in, os.Stdout)
+	if ui != nil {
+		ui.close()
+	}
+	stop()
+	interrupted := errors.Is(err, context.Canceled)
+	if interrupted {
+		fmt.Println("\n" + messages.Interrupted)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	// A quiz which was stopped after being saved is recorded once it is
+	// finished, and a finished quiz doesn't need its checkpoint any more
+	if interrupted && paused {
+		fmt.Printf(messages.CarryOnLater+"\n", config.CheckpointFile)
+		return
+	}
+	if paused {
+		removeCheckpoint(config.CheckpointFile)
+	}
+	if checkpoint != nil {
+		removeCheckpoint(config.ResumeFile)
+	}
+
+	fmt.Printf(messages.Score+"\n", formatPoints(result.Points()), formatPoints(result.MaxPoints))
+	if adaptive != nil {
+		fmt.Printf(messages.Skill+"\n", adaptive.Rating(), adaptive.Level())
+	}
+	if !config.Practice {
+		messages.WriteMissed(os.Stdout, result)
+	}
+
+	if scheduler != nil {
+		for _, a := range result.Answers {
+			scheduler.Review(bank, a)
+		}
+		if err := scheduler.Save(config.ReviewFile); err != nil {
+			log.Printf("Couldn't save the review schedule: %v", err)
+		}
+	}
+
+	rec := quiz.NewRecord(result, bank, config.User, time.Now())
+	if config.HistoryFile != "" {
+		if err := quiz.OpenHistory(config.HistoryFile).Add(rec); err != nil {
+			log.Printf("Couldn't record the result: %v", err)
+		}
+	}
+	if config.Report != "" {
+		if err := writeReport(config.Report, config.Out, rec); err != nil {
+			log.Fatalf("Couldn't write the report: %v", err)
+		}
+		if config.SignKeyFile != "" {
+			key, err := quiz.LoadKey(config.SignKeyFile)
+			if err != nil {
+				log.Fatalf("Couldn't sign the report: %v", err)
+			}
+			if err := quiz.SignFile(key, config.Out); err != nil {
+				log.Fatalf("Couldn't sign the report: %v", err)
+			}
+		}
+	}
+}
+
+// writeReport writes the report to the file at path, or to stdout when
+// path is empty.
+func writeReport(format, path string, rec quiz.Record) error {
+	if path == "" {
+		fmt.Println()
+		return quiz.WriteReport(os.Stdout, format, rec)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := quiz.WriteReport(f, format, rec); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func removeCheckpoint(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Couldn't remove the checkpoint: %v", err)
+	}
+}
+
+// formatPoints prints whole scores without decimals and partial credit to
+// two decimal places.
+func formatPoints(points float64) string {
+	if points == float64(int(points)) {
+		return strconv.Itoa(int(points))
+	}
+	return strconv.FormatFloat(points, 'f', 2, 64)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julianchong00/quiz"
+)
+
+// runSeal writes a copy of a bank with its answers sealed, so that it can
+// be handed out without giving the answers away.
+func runSeal(args []string) {
+	fs := flag.NewFlagSet("seal", flag.ExitOnError)
+	out := fs.String("out", "", "the file to write the sealed bank to (default the bank with .sealed before its extension)")
+	passphraseFile := fs.String(
+		"passphrase-file",
+		"",
+		"the file holding the passphrase answers which can't be hashed are encrypted with",
+	)
+	match := fs.String("match", DefaultMatcher, "how answers to problems without a matcher of their own are checked, as when running the quiz")
+	normalize := fs.String("normalize", DefaultNormalize, "how answers are normalized before they are hashed, as when running the quiz")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: quiz seal [flags] bank\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	matcher, err := quiz.ParseMatcher(*match)
+	if err != nil {
+		log.Fatal(err)
+	}
+	normalization, err := quiz.ParseNormalization(*normalize)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := quiz.SealOptions{
+		Matcher:       matcher,
+		Normalization: normalization,
+		Passphrase:    readPassphrase(*passphraseFile),
+	}
+
+	bank := fs.Arg(0)
+	if *out == "" {
+		ext := filepath.Ext(bank)
+		*out = strings.TrimSuffix(bank, ext) + ".sealed" + ext
+	}
+	if filepath.Ext(*out) != filepath.Ext(bank) {
+		log.Fatalf("-out %s must have the same extension as %s", *out, bank)
+	}
+	if _, err := os.Stat(bank); err != nil {
+		log.Fatal(err)
+	}
+	doc, err := quiz.OpenBankDocument(bank)
+	if err != nil {
+		log.Fatalf("Couldn't open the problem bank: %v", err)
+	}
+
+	hashed, encrypted := 0, 0
+	for i, p := range doc.Problems() {
+		sealed, err := quiz.Seal(p, opts)
+		if err != nil {
+			log.Fatalf("Couldn't seal problem %d, %q: %v", i+1, p.Question, err)
+		}
+		if sealed.IsEncrypted() {
+			encrypted++
+		} else {
+			hashed++
+		}
+		if p.IsSealed() {
+			continue
+		}
+		if err := doc.Set(i, sealed); err != nil {
+			log.Fatalf("Couldn't seal problem %d, %q: %v", i+1, p.Question, err)
+		}
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Sealed %d problems in %s, %d hashed and %d encrypted\n", doc.Len(), *out, hashed, encrypted)
+}
+
+// readPassphrase reads the passphrase sealed answers are encrypted with
+// from a file, or returns "" when no file is given. Surrounding whitespace
+// is ignored, as it is for keys.
+func readPassphrase(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	passphrase := strings.TrimSpace(string(data))
+	if passphrase == "" {
+		log.Fatalf("%s: the passphrase is empty", path)
+	}
+	return passphrase
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julianchong00/quiz"
+)
+
+// runServe serves the quiz as a web application.
+func runServe(args []string) {
+	config := defaultConfig()
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	config.addQuizFlags(fs)
+	port := fs.Int("port", 3000, "the port to start the quiz web application on")
+	reveal := fs.Int("reveal", 5, "seconds to show the answer and leaderboard between problems in live rooms")
+	fs.Parse(args)
+
+	// Check the bank and flags once up front, so that mistakes are found
+	// before the first session instead of during it
+	base := config.newRand()
+	config.quizOptions(base)
+	bank := config.loadBank(base)
+	config.pickProblems(bank, base)
+
+	// rand.Rand isn't safe for concurrent use, so every session gets its
+	// own, seeded from base
+	var mu sync.Mutex
+	newQuiz := func() *quiz.Quiz {
+		mu.Lock()
+		rnd := rand.New(rand.NewSource(base.Int63()))
+		mu.Unlock()
+
+		// Every session gets its own sample of the bank, and generated
+		// problems are made up again
+		problems := bank
+		if config.Generate != "" {
+			problems = config.loadBank(rnd)
+		}
+		problems = config.pickProblems(problems, rnd)
+		opts := append(config.quizOptions(rnd), config.orderOptions(problems, rnd)...)
+		return quiz.New(problems, opts...)
+	}
+
+	rooms := quiz.NewRoomManager(newQuiz, quiz.WithRevealTime(time.Duration(*reveal)*time.Second))
+	roomHandler := quiz.NewRoomHandler(rooms)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", quiz.NewHandler(newQuiz))
+	mux.Handle("/api/rooms", roomHandler)
+	mux.Handle("/api/rooms/", roomHandler)
+	mux.Handle("/ws/", roomHandler)
+
+	fmt.Printf("Starting the server on port: %d\n", *port)
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), mux))
+}
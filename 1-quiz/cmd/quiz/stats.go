@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/julianchong00/quiz"
+)
+
+// defaultHistoryFile is where results are recorded unless -history is
+// given, falling back to the current directory when there is no home
+// directory.
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "quiz_history.jsonl"
+	}
+	return filepath.Join(home, ".quiz", "history.jsonl")
+}
+
+// defaultReviewFile is where the review schedule is kept unless
+// -review-file is given.
+func defaultReviewFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "quiz_review.json"
+	}
+	return filepath.Join(home, ".quiz", "review.json")
+}
+
+func defaultUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// runStats prints statistics about the recorded quiz runs.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	historyFile := fs.String("history", defaultHistoryFile(), "the file results are recorded in")
+	bank := fs.String("bank", "", "only include runs of this bank")
+	userName := fs.String("user", "", "only include runs by this user")
+	runs := fs.Int("runs", 10, "the number of recent runs to show")
+	top := fs.Int("top", 5, "the number of problems to show in each list")
+	fs.Parse(args)
+
+	records, err := quiz.OpenHistory(*historyFile).Records()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var filtered []quiz.Record
+	for _, r := range records {
+		if *bank != "" && r.Bank != *bank {
+			continue
+		}
+		if *userName != "" && r.User != *userName {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	if len(filtered) == 0 {
+		fmt.Printf("No quiz runs recorded in %s.\n", *historyFile)
+		return
+	}
+
+	summary := quiz.Summarize(filtered)
+	printStats(summary, *runs, *top)
+}
+
+func printStats(s quiz.Summary, runs, top int) {
+	fmt.Printf("Runs: %d, overall accuracy: %s\n", len(s.Runs), percent(s.Accuracy()))
+	if older, newer := s.Trend(); len(s.Runs) >= 2 {
+		fmt.Printf("Trend: %s in older runs, %s in newer runs\n", percent(older), percent(newer))
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Println("\nRecent runs:")
+	recent := s.Runs
+	if len(recent) > runs {
+		recent = recent[len(recent)-runs:]
+	}
+	for _, r := range recent {
+		fmt.Fprintf(
+			tw, "  %s\t%s\t%s\t%s/%d\t%s\n",
+			r.Time.Local().Format("2006-01-02 15:04"),
+			r.User,
+			r.Bank,
+			formatPoints(r.Points),
+			r.Total,
+			percent(r.Accuracy()),
+		)
+	}
+	tw.Flush()
+
+	fmt.Println("\nMost missed:")
+	printProblems(tw, s.MostMissed(top))
+
+	fmt.Println("\nSlowest:")
+	printProblems(tw, s.Slowest(top))
+}
+
+func printProblems(tw *tabwriter.Writer, problems []quiz.ProblemStats) {
+	if len(problems) == 0 {
+		fmt.Println("  none")
+		return
+	}
+	fmt.Fprintln(tw, "  question\tasked\tmissed\taccuracy\tavg time")
+	for _, p := range problems {
+		fmt.Fprintf(
+			tw, "  %s\t%d\t%d\t%s\t%s\n",
+			p.Question,
+			p.Asked,
+			p.Missed,
+			percent(p.Accuracy()),
+			p.AverageLatency().Round(100*time.Millisecond),
+		)
+	}
+	tw.Flush()
+}
+
+func percent(f float64) string {
+	return fmt.Sprintf("%.0f%%", f*100)
+}
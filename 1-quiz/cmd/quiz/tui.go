@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/julianchong00/quiz"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used to draw the terminal UI
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiRed       = "\x1b[31m"
+	ansiGreen     = "\x1b[32m"
+	ansiYellow    = "\x1b[33m"
+	ansiHome      = "\x1b[H"
+	ansiClearLine = "\x1b[K"
+	ansiClearDown = "\x1b[J"
+	ansiAltScreen = "\x1b[?1049h"
+	ansiMainPage  = "\x1b[?1049l"
+)
+
+// tuiRefresh is how often the countdown is redrawn
+const tuiRefresh = 100 * time.Millisecond
+
+// maxBarWidth is the widest a countdown bar is drawn
+const maxBarWidth = 40
+
+// tui is a quiz.Display which draws the quiz full screen in a raw mode
+// terminal, with a countdown bar which moves as time runs out. It does its
+// own line editing and passes every line typed on to the quiz, which reads
+// them from the tui along with when each key was pressed.
+type tui struct {
+	// r is read by the quiz for the answers typed
+	r *io.PipeReader
+	w *io.PipeWriter
+
+	mu    sync.Mutex
+	out   *os.File
+	state *term.State
+	// clock is the quiz's, which the deadlines shown are measured by
+	clock    quiz.Clock
+	practice bool
+	messages quiz.Messages
+	event    quiz.Event
+	// pausedAt is when the quiz was paused, which the countdown bars stay
+	// at until it carries on
+	pausedAt time.Time
+	noHints  bool
+	feedback string
+	input    []rune
+	// pressed are the times the keys of the line being typed were
+	// pressed, and keystrokes are the timings of the lines typed which
+	// the quiz hasn't read yet
+	pressed    []time.Time
+	keystrokes [][]time.Duration
+	done       chan struct{}
+	closed     bool
+}
+
+// Read reads the lines typed, so that the tui can be the input of the
+// quiz.
+func (t *tui) Read(p []byte) (int, error) {
+	return t.r.Read(p)
+}
+
+// Keystrokes returns the times between the keys pressed for the next line
+// read, which makes the tui a quiz.KeystrokeReader.
+func (t *tui) Keystrokes() []time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.keystrokes) == 0 {
+		return nil
+	}
+	keys := t.keystrokes[0]
+	t.keystrokes = t.keystrokes[1:]
+	return keys
+}
+
+// canUseTUI reports whether both ends of the quiz are a terminal. The
+// plain line by line quiz is used otherwise, such as when answers are
+// piped in.
+func canUseTUI() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newTUI creates a UI for the quiz run with the given clock. It doesn't
+// touch the terminal until open is called.
+func newTUI(clock quiz.Clock, practice bool, messages quiz.Messages) *tui {
+	r, w := io.Pipe()
+	return &tui{
+		r:        r,
+		w:        w,
+		out:      os.Stdout,
+		clock:    clock,
+		practice: practice,
+		messages: messages,
+		done:     make(chan struct{}),
+	}
+}
+
+// open puts the terminal into raw mode and takes over the screen until
+// close is called. interrupt is called when the user presses Ctrl+C, which
+// no longer sends a signal in raw mode.
+func (t *tui) open(interrupt func()) error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	t.state = state
+	fmt.Fprint(t.out, ansiAltScreen)
+
+	go t.readKeys(interrupt)
+	go func() {
+		ticker := time.NewTicker(tuiRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-ticker.C:
+				t.mu.Lock()
+				t.draw()
+				t.mu.Unlock()
+			}
+		}
+	}()
+	return nil
+}
+
+// close gives the screen back and restores the terminal.
+func (t *tui) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	close(t.done)
+	t.w.Close()
+	fmt.Fprint(t.out, ansiMainPage)
+	term.Restore(int(os.Stdin.Fd()), t.state)
+}
+
+func (t *tui) Show(e quiz.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e.Number != t.event.Number {
+		t.noHints = false
+	}
+	switch e.Kind {
+	case quiz.EventNoHints:
+		t.noHints = true
+	case quiz.EventAnswer, quiz.EventProblemTimeUp:
+		t.feedback = t.describe(e.Answer)
+	case quiz.EventPause:
+		// The deadlines of a paused quiz are zero, so the ones from before
+		// it was paused are shown stopped instead
+		t.pausedAt = t.clock.Now()
+		e.Deadline = t.event.Deadline
+		e.ProblemDeadline = t.event.ProblemDeadline
+	}
+	t.event = e
+	t.draw()
+}
+
+// describe returns the colored feedback for an answer. The answer is only
+// given away in practice mode.
+func (t *tui) describe(a quiz.Answer) string {
+	m := t.messages
+	color := ansiRed
+	if a.Correct {
+		color = ansiGreen
+	} else if a.Credit > 0 {
+		color = ansiYellow
+	}
+
+	var text string
+	switch {
+	case a.TimedOut:
+		text = m.ProblemTimeUp
+	case a.Correct || t.practice:
+		text = m.Feedback(a)
+	default:
+		text = m.Incorrect
+	}
+	if t.practice && !a.Correct {
+		if a.TimedOut {
+			text += " " + m.Feedback(a)
+		}
+		if a.Problem.Explanation != "" {
+			text += ansiReset + "\n" + a.Problem.Explanation
+		}
+	}
+	return color + text + ansiReset
+}
+
+// draw redraws the whole screen. Lines are overwritten in place rather
+// than clearing the screen first, so that it doesn't flicker. t.mu must
+// be held.
+func (t *tui) draw() {
+	if t.closed {
+		return
+	}
+	e := t.event
+	width, _, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 {
+		width = 80
+	}
+
+	m := t.messages
+	var lines []string
+	header := ansiBold + m.Title + ansiReset
+	if e.Number > 0 {
+		header += "    " + fmt.Sprintf(m.Progress, e.Number, e.Total) +
+			"    " + fmt.Sprintf(m.RunningScore, formatPoints(e.Points), formatPoints(e.Possible))
+	}
+	lines = append(lines, header, "")
+
+	now := t.clock.Now()
+	paused := e.Kind == quiz.EventPause
+	if paused {
+		now = t.pausedAt
+	}
+	// The labels are padded to the same width so that the bars line up
+	labelWidth := utf8.RuneCountInString(m.QuizTimer)
+	if n := utf8.RuneCountInString(m.ProblemTimer); n > labelWidth {
+		labelWidth = n
+	}
+	if e.TimeLimit > 0 {
+		label := fmt.Sprintf("%-*s", labelWidth, m.QuizTimer)
+		lines = append(lines, t.countdownBar(label, e.Deadline, e.TimeLimit, now, width, paused))
+	}
+	if e.ProblemTimeLimit > 0 && e.Number > 0 {
+		label := fmt.Sprintf("%-*s", labelWidth, m.ProblemTimer)
+		lines = append(lines, t.countdownBar(label, e.ProblemDeadline, e.ProblemTimeLimit, now, width, paused))
+	}
+	lines = append(lines, "")
+
+	switch {
+	case e.Kind == quiz.EventStart:
+		lines = append(lines, m.Start)
+	case e.Kind == quiz.EventTimeUp:
+		lines = append(lines, ansiRed+ansiBold+m.TimeUp+ansiReset)
+	default:
+		lines = append(lines, ansiBold+e.Problem.Question+ansiReset)
+		for i, o := range e.Order {
+			lines = append(lines, fmt.Sprintf("  %c) %s", 'A'+i, e.Problem.Options[o]))
+		}
+		if e.Problem.IsMultiSelect() {
+			lines = append(lines, ansiDim+strings.TrimRight(m.ChooseMany, ": ")+ansiReset)
+		}
+		for i := 0; i < e.Hints && i < len(e.Problem.Hints); i++ {
+			lines = append(lines, ansiYellow+fmt.Sprintf(m.Hint, i+1, len(e.Problem.Hints), e.Problem.Hints[i])+ansiReset)
+		}
+		if t.noHints {
+			lines = append(lines, ansiDim+m.NoHints+ansiReset)
+		}
+	}
+	lines = append(lines, "")
+	if t.feedback != "" {
+		lines = append(lines, strings.Split(t.feedback, "\n")...)
+	}
+	if e.Kind == quiz.EventPause {
+		lines = append(lines, "", ansiYellow+strings.ReplaceAll(e.Message, "\n", " ")+ansiReset)
+	}
+	lines = append(lines, "",
+		ansiDim+fmt.Sprintf(m.Help, quiz.HintCommand, quiz.PauseCommand)+ansiReset,
+		"> "+string(t.input),
+	)
+
+	// Raw mode doesn't turn \n into \r\n, and the cursor is left at the
+	// end of the answer being typed
+	var b strings.Builder
+	b.WriteString(ansiHome)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(ansiClearLine)
+	}
+	b.WriteString(ansiClearDown)
+	io.WriteString(t.out, b.String())
+}
+
+// countdownBar draws how much time is left before the deadline as a bar
+// which turns from green to yellow to red. The whole limit is left when
+// there is no deadline yet.
+func (t *tui) countdownBar(label string, deadline time.Time, limit time.Duration, now time.Time, width int, paused bool) string {
+	left := limit
+	if !deadline.IsZero() {
+		left = deadline.Sub(now)
+	}
+	if left < 0 {
+		left = 0
+	}
+	if left > limit {
+		left = limit
+	}
+
+	barWidth := width - utf8.RuneCountInString(label) - 16
+	if barWidth > maxBarWidth {
+		barWidth = maxBarWidth
+	}
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	filled := int(float64(barWidth) * float64(left) / float64(limit))
+
+	color := ansiGreen
+	switch {
+	case left*5 <= limit:
+		color = ansiRed
+	case left*2 <= limit:
+		color = ansiYellow
+	}
+	status := fmt.Sprintf(t.messages.TimeLeft, fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60))
+	if paused {
+		status = t.messages.TimerPaused
+	}
+	return fmt.Sprintf("%s [%s%s%s%s] %s",
+		label, color, strings.Repeat("█", filled), ansiReset, strings.Repeat("░", barWidth-filled), status)
+}
+
+// readKeys edits the line being typed, since raw mode leaves that to the
+// program, and passes each finished line on to the quiz.
+func (t *tui) readKeys(interrupt func()) {
+	keys := bufio.NewReader(os.Stdin)
+	escape := false
+	for {
+		key, _, err := keys.ReadRune()
+		if err != nil {
+			t.w.CloseWithError(err)
+			return
+		}
+
+		// Escape sequences, such as the arrow keys, are ignored
+		if escape {
+			escape = key == '[' || !(unicode.IsLetter(key) || key == '~')
+			continue
+		}
+
+		var line string
+		send := false
+		t.mu.Lock()
+		switch {
+		case key == '\x1b':
+			escape = true
+		case key == '\x03':
+			// Ctrl+C
+			t.mu.Unlock()
+			interrupt()
+			continue
+		case key == '\x04' && len(t.input) == 0:
+			// Ctrl+D ends the input like it would in a terminal
+			t.mu.Unlock()
+			t.w.Close()
+			return
+		case key == '\r' || key == '\n':
+			line = string(t.input)
+			t.input = t.input[:0]
+			send = true
+			// The timings are queued before the line is, so they are
+			// there once the quiz has read it
+			var gaps []time.Duration
+			for i := 1; i < len(t.pressed); i++ {
+				gaps = append(gaps, t.pressed[i].Sub(t.pressed[i-1]))
+			}
+			t.keystrokes = append(t.keystrokes, gaps)
+			t.pressed = t.pressed[:0]
+		case key == '\x7f' || key == '\b':
+			if len(t.input) > 0 {
+				t.input = t.input[:len(t.input)-1]
+			}
+			t.pressed = append(t.pressed, t.clock.Now())
+		case key == '\x15':
+			// Ctrl+U clears the line
+			t.input = t.input[:0]
+		case unicode.IsPrint(key):
+			t.input = append(t.input, key)
+			t.pressed = append(t.pressed, t.clock.Now())
+		}
+		t.draw()
+		t.mu.Unlock()
+
+		// The quiz reads lines in its own goroutine, and may be showing
+		// the next problem while this waits for it
+		if send {
+			if _, err := io.WriteString(t.w, line+"\n"); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Direction is which way round problems are asked.
+type Direction int
+
+const (
+	// Forward shows the question and expects the answer
+	Forward Direction = iota
+	// Reverse shows the answer and expects the question, as when
+	// learning vocabulary both ways
+	Reverse
+	// Both asks every problem forward and in reverse
+	Both
+)
+
+// DefaultReverseMatcher checks the answers to reversed problems unless
+// the quiz is given another one with WithReverseMatcher. The matcher of a
+// problem only applies to its answer, so reversed problems never use it.
+var DefaultReverseMatcher Matcher = CaseInsensitive{}
+
+func (d Direction) String() string {
+	switch d {
+	case Forward:
+		return "forward"
+	case Reverse:
+		return "reverse"
+	case Both:
+		return "both"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// ParseDirection parses forward, reverse or both.
+func ParseDirection(s string) (Direction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "forward":
+		return Forward, nil
+	case "reverse":
+		return Reverse, nil
+	case "both":
+		return Both, nil
+	}
+	return Forward, fmt.Errorf("unknown direction %q, expected forward, reverse or both", s)
+}
+
+// WithReverseMatcher sets the matcher used for reversed problems.
+func WithReverseMatcher(m Matcher) Option {
+	return func(q *Quiz) {
+		q.reverseMatcher = m
+	}
+}
+
+// CanReverse reports whether the problem can be asked in reverse. The
+// answers to multiple choice problems are option letters, the answers to
+// regex problems are patterns and the answers to anyof problems are lists
+// such as "yes|y", none of which make sense as a question, and sealed
+// answers can't be shown.
+func (p Problem) CanReverse() bool {
+	if p.Reversed || p.IsMultipleChoice() || p.IsSealed() {
+		return false
+	}
+	switch p.Matcher.(type) {
+	case Regex, AnyOf:
+		return false
+	}
+	return true
+}
+
+// Reverse returns the problem the other way round, showing the answer and
+// expecting the question. The hints are left out as they lead to the
+// answer rather than the question.
+func (p Problem) Reverse() Problem {
+	r := p
+	r.Question, r.Answer = p.Answer, p.Question
+	r.Alternatives = nil
+	r.Hints = nil
+	r.Matcher = nil
+	r.Reversed = true
+	return r
+}
+
+// Directed returns the problems to ask in the direction d. Problems which
+// can't be reversed are asked forward, and with Both the reversed problems
+// follow all of the forward ones.
+func Directed(problems []Problem, d Direction) []Problem {
+	if d == Forward {
+		return problems
+	}
+	var directed []Problem
+	if d == Both {
+		directed = append(directed, problems...)
+	}
+	for _, p := range problems {
+		switch {
+		case p.CanReverse():
+			directed = append(directed, p.Reverse())
+		case d == Reverse:
+			directed = append(directed, p)
+		}
+	}
+	return directed
+}
+
+// DirectionSummary is how well the problems asked in one direction went.
+type DirectionSummary struct {
+	Direction string `json:"direction"`
+	Asked     int    `json:"asked"`
+	Right     int    `json:"right"`
+}
+
+// Accuracy returns the fraction of the problems asked which were answered
+// right.
+func (s DirectionSummary) Accuracy() float64 {
+	if s.Asked == 0 {
+		return 0
+	}
+	return float64(s.Right) / float64(s.Asked)
+}
+
+// Directions breaks the answers of the run down by the direction they were
+// asked in. It returns nil when no problem was reversed.
+func (r Record) Directions() []DirectionSummary {
+	summaries := []DirectionSummary{{Direction: Forward.String()}, {Direction: Reverse.String()}}
+	for _, a := range r.Answers {
+		s := &summaries[0]
+		if a.Reversed {
+			s = &summaries[1]
+		}
+		s.Asked++
+		if a.Correct {
+			s.Right++
+		}
+	}
+	if summaries[1].Asked == 0 {
+		return nil
+	}
+	return summaries
+}
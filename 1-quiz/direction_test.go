@@ -0,0 +1,24 @@
+package quiz
+
+import "testing"
+
+func TestCanReverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem Problem
+		want    bool
+	}{
+		{"plain", Problem{Question: "perro", Answer: "dog"}, true},
+		{"alternatives", Problem{Question: "perro", Answer: "dog", Alternatives: []string{"hound"}}, true},
+		{"numeric", Problem{Question: "pi", Answer: "3.14", Matcher: Numeric{Tolerance: 0.01}}, true},
+		{"any of", Problem{Question: "Is 7 prime?", Answer: "yes|y", Matcher: AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}}, false},
+		{"regex", Problem{Question: "colour", Answer: "colou?r", Matcher: Regex{}}, false},
+		{"multiple choice", Problem{Question: "5+5", Options: []string{"9", "10"}, Answer: "B"}, false},
+		{"reversed", Problem{Question: "dog", Answer: "perro", Reversed: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.problem.CanReverse(); got != tt.want {
+			t.Errorf("%s: CanReverse() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package quiz
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// EventKind says what has happened in a run of a quiz.
+type EventKind int
+
+const (
+	// EventStart waits for the user to press enter to start the quiz
+	EventStart EventKind = iota
+	// EventAsk asks a problem. The same problem is asked again after a
+	// hint or a pause.
+	EventAsk
+	// EventHint reveals the next hint for the problem
+	EventHint
+	// EventNoHints says there are no more hints for the problem
+	EventNoHints
+	// EventAnswer follows an answer being given
+	EventAnswer
+	// EventProblemTimeUp says the problem ran out of time
+	EventProblemTimeUp
+	// EventTimeUp says the whole quiz ran out of time
+	EventTimeUp
+	// EventPause waits for the user to press enter to carry on
+	EventPause
+)
+
+// Event is what a Display is shown as the quiz runs.
+type Event struct {
+	Kind EventKind
+	// Number is the problem being asked counting from 1, and Total is how
+	// many problems will be asked
+	Number int
+	Total  int
+	// Problem is the problem being asked, with the options of a multiple
+	// choice problem shown in Order
+	Problem Problem
+	Order   []int
+	// Hints is how many of the problem's hints have been revealed
+	Hints int
+	// Answer is the answer just given, for EventAnswer and
+	// EventProblemTimeUp
+	Answer Answer
+	// Points is the score so far, out of Possible for the problems which
+	// have been answered
+	Points   float64
+	Possible float64
+	// Deadline is when the quiz runs out of time and ProblemDeadline is
+	// when this problem does, counting down from TimeLimit and
+	// ProblemTimeLimit. Either is zero when there is no limit or while
+	// the quiz is paused.
+	Deadline         time.Time
+	ProblemDeadline  time.Time
+	TimeLimit        time.Duration
+	ProblemTimeLimit time.Duration
+	// Message says whether the quiz could be saved, for EventPause
+	Message string
+}
+
+// Display shows a quiz to the user as it runs. Run writes plain lines to
+// its writer unless it is given another Display with WithDisplay.
+type Display interface {
+	// Show is called from the goroutine running the quiz, which waits for
+	// it to return.
+	Show(e Event)
+}
+
+// WithDisplay shows the quiz with d instead of writing lines to the writer
+// given to Run. Answers are still read from Run's reader.
+func WithDisplay(d Display) Option {
+	return func(q *Quiz) {
+		q.display = d
+	}
+}
+
+// lineDisplay writes the quiz a line at a time, as a terminal or a pipe
+// would show it.
+type lineDisplay struct {
+	w        io.Writer
+	practice bool
+	messages Messages
+}
+
+func (d lineDisplay) Show(e Event) {
+	switch e.Kind {
+	case EventStart:
+		fmt.Fprint(d.w, d.messages.Start)
+	case EventAsk:
+		if e.Problem.IsMultipleChoice() {
+			fmt.Fprintf(d.w, d.messages.Problem+"\n", e.Number, e.Problem.Question)
+			d.messages.writeOptions(d.w, e.Problem, e.Order)
+		} else {
+			fmt.Fprintf(d.w, d.messages.Problem+" = ", e.Number, e.Problem.Question)
+		}
+	case EventHint:
+		d.messages.writeHint(d.w, e.Problem, e.Hints-1)
+	case EventNoHints:
+		fmt.Fprintln(d.w, d.messages.NoHints)
+	case EventAnswer:
+		if d.practice {
+			d.messages.writeFeedback(d.w, e.Answer)
+		}
+	case EventProblemTimeUp:
+		fmt.Fprintln(d.w, "\n"+d.messages.ProblemTimeUp)
+		if d.practice {
+			d.messages.writeFeedback(d.w, e.Answer)
+		}
+	case EventTimeUp:
+		fmt.Fprintln(d.w, "\n"+d.messages.TimeUp)
+	case EventPause:
+		fmt.Fprint(d.w, e.Message)
+	}
+}
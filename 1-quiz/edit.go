@@ -0,0 +1,896 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// BankDocument is a problem bank file opened for editing. Problems which
+// aren't changed are written back exactly as they were, along with the
+// comments and blank lines around them, so editing one problem doesn't
+// reformat the rest of the bank.
+type BankDocument struct {
+	path string
+	ext  string
+	// original is the bank as it was read, or nil for a new bank
+	original []byte
+	// prefix and suffix are the text before and after the problems
+	prefix, suffix string
+	entries        []docEntry
+	// indent is the indentation of the problems of a YAML or JSON bank
+	indent string
+	// lead, sep and trail are the text before, between and after the
+	// problems of a JSON bank
+	lead, sep, trail string
+	// header is the columns of a csv bank, or nil when it has none
+	header []string
+	// newline ends the lines of the bank, "\r\n" when it was written
+	// with Windows line endings and "\n" otherwise
+	newline string
+	changed bool
+}
+
+// docEntry is a problem in a BankDocument.
+type docEntry struct {
+	problem Problem
+	// raw is the problem as it is written in the bank, or "" once it has
+	// been changed
+	raw string
+	// fields is how many columns the problem has in a csv bank
+	fields int
+}
+
+// OpenBankDocument reads the csv, JSON, YAML or TOML bank at path for
+// editing. A bank which doesn't exist yet is created when it is saved.
+// The bank has to load without errors, and YAML banks have to write the
+// problems list in block style, so that every problem can be found.
+func OpenBankDocument(path string) (*BankDocument, error) {
+	d := &BankDocument{path: path, ext: strings.ToLower(filepath.Ext(path)), newline: "\n"}
+	switch d.ext {
+	case ".csv", ".json", ".yaml", ".yml", ".toml":
+	default:
+		return nil, fmt.Errorf("%s: can't edit %q banks", path, d.ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		d.init()
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	problems, err := loaders[d.ext](bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	d.original = data
+	if bytes.Contains(data, []byte("\r\n")) {
+		d.newline = "\r\n"
+	}
+
+	switch d.ext {
+	case ".csv":
+		err = d.splitCSV(data, problems)
+	case ".json":
+		err = d.splitJSON(data, problems)
+	case ".yaml", ".yml":
+		err = d.splitYAML(data, problems)
+	case ".toml":
+		err = d.splitTOML(data, problems)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// init lays out a bank which doesn't have any problems yet.
+func (d *BankDocument) init() {
+	switch d.ext {
+	case ".json":
+		d.prefix, d.suffix = "{\n  \"problems\": [", "]\n}\n"
+		d.indent = "    "
+		d.lead, d.sep, d.trail = "\n"+d.indent, ",\n"+d.indent, "\n  "
+	case ".yaml", ".yml":
+		d.prefix = "problems:\n"
+		d.indent = "  "
+	}
+}
+
+// Path returns the path of the bank.
+func (d *BankDocument) Path() string {
+	return d.path
+}
+
+// Len returns how many problems the bank has.
+func (d *BankDocument) Len() int {
+	return len(d.entries)
+}
+
+// Problem returns the i'th problem of the bank, counting from 0.
+func (d *BankDocument) Problem(i int) Problem {
+	return d.entries[i].problem
+}
+
+// Problems returns the problems of the bank in order.
+func (d *BankDocument) Problems() []Problem {
+	problems := make([]Problem, len(d.entries))
+	for i, e := range d.entries {
+		problems[i] = e.problem
+	}
+	return problems
+}
+
+// Changed reports whether the bank has changed since it was read or last
+// saved.
+func (d *BankDocument) Changed() bool {
+	return d.changed
+}
+
+// Add appends a problem to the bank.
+func (d *BankDocument) Add(p Problem) error {
+	e, err := d.entry(p, -1)
+	if err != nil {
+		return err
+	}
+	d.entries = append(d.entries, e)
+	d.changed = true
+	return nil
+}
+
+// Set replaces the i'th problem of the bank.
+func (d *BankDocument) Set(i int, p Problem) error {
+	if err := d.checkIndex(i); err != nil {
+		return err
+	}
+	e, err := d.entry(p, i)
+	if err != nil {
+		return err
+	}
+	d.entries[i] = e
+	d.changed = true
+	return nil
+}
+
+// Delete removes the i'th problem from the bank.
+func (d *BankDocument) Delete(i int) error {
+	if err := d.checkIndex(i); err != nil {
+		return err
+	}
+	d.entries = append(d.entries[:i], d.entries[i+1:]...)
+	d.changed = true
+	return nil
+}
+
+// Move moves the problem at from so that it is at to, shifting the
+// problems in between.
+func (d *BankDocument) Move(from, to int) error {
+	if err := d.checkIndex(from); err != nil {
+		return err
+	}
+	if err := d.checkIndex(to); err != nil {
+		return err
+	}
+	e := d.entries[from]
+	d.entries = append(d.entries[:from], d.entries[from+1:]...)
+	d.entries = append(d.entries[:to], append([]docEntry{e}, d.entries[to:]...)...)
+	d.changed = d.changed || from != to
+	return nil
+}
+
+func (d *BankDocument) checkIndex(i int) error {
+	if i < 0 || i >= len(d.entries) {
+		return fmt.Errorf("no problem %d, the bank has %d", i+1, len(d.entries))
+	}
+	return nil
+}
+
+// entry validates p as a problem of the bank, replacing the problem at
+// index i or added to the end when i is -1.
+func (d *BankDocument) entry(p Problem, i int) (docEntry, error) {
+	rec, err := bankEntryFor(p)
+	if err != nil {
+		return docEntry{}, err
+	}
+	checked, err := rec.record().problem()
+	if err != nil {
+		return docEntry{}, err
+	}
+	if checked.ID != "" {
+		for j, e := range d.entries {
+			if j != i && e.problem.ID == checked.ID {
+				return docEntry{}, fmt.Errorf("duplicate id %q, already used by problem %d", checked.ID, j+1)
+			}
+		}
+	}
+	if d.ext == ".csv" {
+		if err := d.addColumns(rec); err != nil {
+			return docEntry{}, err
+		}
+	}
+	return docEntry{problem: checked}, nil
+}
+
+// Bytes returns the bank as it will be saved. A bank which hasn't changed
+// is returned exactly as it was read.
+func (d *BankDocument) Bytes() ([]byte, error) {
+	if !d.changed && d.original != nil {
+		return d.original, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(d.prefix)
+	if d.ext == ".json" && len(d.entries) > 0 {
+		b.WriteString(d.lead)
+	}
+	for i, e := range d.entries {
+		text := e.raw
+		if text == "" {
+			var err error
+			if text, err = d.render(e.problem); err != nil {
+				return nil, fmt.Errorf("problem %d: %w", i+1, err)
+			}
+			text = d.lines(text)
+		}
+		// Blank lines which kept the last problem apart from the next
+		// one aren't needed once it has been moved to the end
+		if i == len(d.entries)-1 && d.ext != ".json" {
+			text = trimBlankLines(text)
+		}
+
+		switch d.ext {
+		case ".json":
+			if i > 0 {
+				b.WriteString(d.sep)
+			}
+		case ".toml":
+			// Tables are kept apart by a blank line
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), d.newline+d.newline) {
+				d.endLine(&b)
+				b.WriteString(d.newline)
+			}
+		default:
+			d.endLine(&b)
+		}
+		b.WriteString(text)
+	}
+	if d.ext == ".json" {
+		b.WriteString(d.trail)
+	} else if len(d.entries) > 0 {
+		d.endLine(&b)
+	}
+	b.WriteString(d.suffix)
+
+	// Make sure what is written can be read back
+	data := []byte(b.String())
+	if _, err := loaders[d.ext](bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("the edited bank can't be read back: %w", err)
+	}
+	return data, nil
+}
+
+// Save writes the bank back to its file.
+func (d *BankDocument) Save() error {
+	data, err := d.Bytes()
+	if err != nil {
+		return fmt.Errorf("%s: %w", d.path, err)
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(d.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(d.path, data, mode); err != nil {
+		return err
+	}
+	d.original = data
+	d.changed = false
+	return nil
+}
+
+// endLine ends the text written so far with a newline if it doesn't have
+// one.
+func (d *BankDocument) endLine(b *strings.Builder) {
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString(d.newline)
+	}
+}
+
+// lines gives text written with "\n" the line endings of the bank.
+func (d *BankDocument) lines(text string) string {
+	if d.newline == "\n" {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", d.newline)
+}
+
+// trimBlankLines removes the blank lines from the end of text, keeping
+// the line ending of the last line which isn't blank.
+func trimBlankLines(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	n := len(lines)
+	for n > 1 && strings.TrimSpace(lines[n-1]) == "" {
+		n--
+	}
+	return strings.Join(lines[:n], "")
+}
+
+// bankEntry is a problem as it is written to a bank, leaving out the fields
+// which aren't set.
+type bankEntry struct {
+	ID          string   `json:"id,omitempty" yaml:"id,omitempty" toml:"id,omitempty"`
+	Question    string   `json:"question" yaml:"question" toml:"question"`
+	Answer      string   `json:"answer" yaml:"answer" toml:"answer"`
+	Answers     []string `json:"answers,omitempty" yaml:"answers,omitempty" toml:"answers,omitempty"`
+	Options     []string `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	MultiSelect bool     `json:"multi_select,omitempty" yaml:"multi_select,omitempty" toml:"multi_select,omitempty"`
+	Hints       []string `json:"hints,omitempty" yaml:"hints,omitempty" toml:"hints,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Difficulty  int      `json:"difficulty,omitempty" yaml:"difficulty,omitempty" toml:"difficulty,omitzero"`
+	TimeLimit   string   `json:"time_limit,omitempty" yaml:"time_limit,omitempty" toml:"time_limit,omitempty"`
+	Match       string   `json:"match,omitempty" yaml:"match,omitempty" toml:"match,omitempty"`
+	Explanation string   `json:"explanation,omitempty" yaml:"explanation,omitempty" toml:"explanation,omitempty"`
+	Weight      float64  `json:"weight,omitempty" yaml:"weight,omitempty" toml:"weight,omitzero"`
+}
+
+// bankEntryFor converts a problem into the form it is written in.
+func bankEntryFor(p Problem) (bankEntry, error) {
+	match, err := MatcherSpec(p.Matcher)
+	if err != nil {
+		return bankEntry{}, err
+	}
+	e := bankEntry{
+		ID:          p.ID,
+		Question:    p.Question,
+		Answer:      p.Answer,
+		Answers:     p.Alternatives,
+		Options:     p.Options,
+		MultiSelect: p.MultiSelect,
+		Hints:       p.Hints,
+		Tags:        p.Tags,
+		Difficulty:  p.Difficulty,
+		Match:       match,
+		Explanation: p.Explanation,
+		Weight:      p.Weight,
+	}
+	if p.TimeLimit > 0 {
+		e.TimeLimit = p.TimeLimit.String()
+	}
+	return e, nil
+}
+
+// record returns the entry as if it had been read from a bank.
+func (e bankEntry) record() bankProblem {
+	rec := bankProblem{
+		ID:          e.ID,
+		Question:    e.Question,
+		Answer:      e.Answer,
+		Answers:     e.Answers,
+		Options:     e.Options,
+		MultiSelect: e.MultiSelect,
+		Hints:       e.Hints,
+		Tags:        e.Tags,
+		Difficulty:  e.Difficulty,
+		Match:       e.Match,
+		Explanation: e.Explanation,
+		Weight:      e.Weight,
+	}
+	if e.TimeLimit != "" {
+		rec.TimeLimit = e.TimeLimit
+	}
+	return rec
+}
+
+// MatcherSpec returns the spec which ParseMatcher turns back into m, or ""
+// for nil. Only the matchers ParseMatcher can return have a spec.
+func MatcherSpec(m Matcher) (string, error) {
+	switch m := m.(type) {
+	case nil:
+		return "", nil
+	case Exact:
+		return "exact", nil
+	case CaseInsensitive:
+		return "nocase", nil
+	case Numeric:
+		if m.Tolerance == 1e-9 {
+			return "numeric", nil
+		}
+		return "numeric:" + strconv.FormatFloat(m.Tolerance, 'g', -1, 64), nil
+	case Regex:
+		return "regex", nil
+	case AnyOf:
+		if _, ok := m.Matcher.(CaseInsensitive); !ok {
+			break
+		}
+		if m.Delimiter == "|" {
+			return "anyof", nil
+		}
+		return "anyof:" + m.Delimiter, nil
+	case Fuzzy:
+		return "fuzzy:" + strconv.Itoa(m.MaxDistance), nil
+	}
+	return "", fmt.Errorf("the %T matcher can't be written to a bank", m)
+}
+
+// render writes a problem in the format of the bank.
+func (d *BankDocument) render(p Problem) (string, error) {
+	e, err := bankEntryFor(p)
+	if err != nil {
+		return "", err
+	}
+	switch d.ext {
+	case ".csv":
+		return d.renderCSV(e)
+	case ".json":
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent(d.indent, "  ")
+		if err := enc.Encode(e); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	case ".yaml", ".yml":
+		out, err := yaml.Marshal([]bankEntry{e})
+		if err != nil {
+			return "", err
+		}
+		lines := strings.SplitAfter(string(out), "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = d.indent + line
+			}
+		}
+		return strings.Join(lines, ""), nil
+	case ".toml":
+		var b bytes.Buffer
+		enc := toml.NewEncoder(&b)
+		enc.Indent = ""
+		err := enc.Encode(struct {
+			Problems []bankEntry `toml:"problems"`
+		}{[]bankEntry{e}})
+		return b.String(), err
+	}
+	return "", fmt.Errorf("can't write %q banks", d.ext)
+}
+
+// headerlessColumns are the columns of a csv bank without a header, in
+// order.
+var headerlessColumns = []string{"question", "answer", "time_limit", "match"}
+
+// csvValues returns the columns of a csv bank the entry needs, with their
+// values. The answer goes in the answers column along with the
+// alternatives when the bank has no answer column.
+func (d *BankDocument) csvValues(e bankEntry) (map[string]string, error) {
+	values := map[string]string{
+		"id":          e.ID,
+		"question":    e.Question,
+		"answer":      e.Answer,
+		"match":       e.Match,
+		"time_limit":  e.TimeLimit,
+		"explanation": e.Explanation,
+	}
+	answers := e.Answers
+	if d.header != nil && !contains(d.header, "answer") && contains(d.header, "answers") {
+		values["answer"] = ""
+		answers = append([]string{e.Answer}, answers...)
+	}
+	lists := map[string][]string{"answers": answers, "options": e.Options, "hints": e.Hints, "tags": e.Tags}
+	for col, list := range lists {
+		for _, v := range list {
+			if strings.Contains(v, csvListSeparator) {
+				return nil, fmt.Errorf("%s can't contain %q in a csv bank", col, csvListSeparator)
+			}
+		}
+		values[col] = strings.Join(list, csvListSeparator)
+	}
+	if e.MultiSelect {
+		values["multi_select"] = "true"
+	}
+	if e.Difficulty != 0 {
+		values["difficulty"] = strconv.Itoa(e.Difficulty)
+	}
+	if e.Weight != 0 {
+		values["weight"] = strconv.FormatFloat(e.Weight, 'g', -1, 64)
+	}
+	for col, v := range values {
+		if v == "" {
+			delete(values, col)
+		}
+	}
+	return values, nil
+}
+
+// addColumns adds the columns the entry needs to the header of a csv bank,
+// giving the bank a header when it doesn't have one and the entry can't
+// be written without it. The other rows are padded with empty cells.
+func (d *BankDocument) addColumns(e bankEntry) error {
+	values, err := d.csvValues(e)
+	if err != nil {
+		return err
+	}
+	columns := d.header
+	if columns == nil {
+		columns = headerlessColumns
+	}
+	var missing []string
+	for _, col := range csvOrder(values) {
+		if !contains(columns, col) {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	hadHeader := d.header != nil
+	d.header = append(append([]string{}, columns...), missing...)
+	d.padRows()
+	if !hadHeader {
+		d.prefix = d.lines(csvLine(d.header)) + d.prefix
+		return nil
+	}
+	// The header is the last line before the first row
+	lines := strings.SplitAfter(d.prefix, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			lines[i] = d.lines(csvLine(d.header))
+			break
+		}
+	}
+	d.prefix = strings.Join(lines, "")
+	return nil
+}
+
+// padRows adds empty cells to the end of the unchanged rows of a csv bank
+// so that they have a cell for every column of the header.
+func (d *BankDocument) padRows() {
+	for i, e := range d.entries {
+		if e.raw == "" || e.fields >= len(d.header) {
+			continue
+		}
+		row := strings.TrimRight(e.raw, "\r\n")
+		d.entries[i].raw = row + strings.Repeat(",", len(d.header)-e.fields) + e.raw[len(row):]
+		d.entries[i].fields = len(d.header)
+	}
+}
+
+func (d *BankDocument) renderCSV(e bankEntry) (string, error) {
+	values, err := d.csvValues(e)
+	if err != nil {
+		return "", err
+	}
+	if d.header != nil {
+		row := make([]string, len(d.header))
+		for i, col := range d.header {
+			row[i] = values[col]
+		}
+		return csvLine(row), nil
+	}
+
+	// Trailing empty columns are left off rows without a header
+	row := make([]string, 0, len(headerlessColumns))
+	for _, col := range headerlessColumns {
+		row = append(row, values[col])
+	}
+	for len(row) > 2 && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return csvLine(row), nil
+}
+
+// csvLine writes a row of a csv bank, ending in a newline.
+func csvLine(row []string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(row)
+	w.Flush()
+	return b.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// csvOrder returns the columns which have values in the order they are
+// added to a header: the columns of a bank without a header first, then
+// the rest in the order of the fields of a problem.
+func csvOrder(values map[string]string) []string {
+	var columns []string
+	order := append(append([]string{}, headerlessColumns...), "id", "answers", "options", "multi_select", "hints", "tags", "difficulty", "explanation", "weight")
+	for _, col := range order {
+		if _, ok := values[col]; ok {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// splitLines splits the text of a bank into lines which keep their line
+// endings.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// split divides a bank into the text before the problems, the text of
+// each problem and the text after them. starts are the lines each problem
+// starts on and end is the line after the last problem, counting from 1.
+// Comments directly above a problem stay with it when comments is set.
+func (d *BankDocument) split(lines []string, problems []Problem, starts []int, end int, comments bool) error {
+	if len(starts) != len(problems) {
+		return fmt.Errorf("only found %d of the %d problems in the bank", len(starts), len(problems))
+	}
+	isComment := func(n int) bool {
+		return strings.HasPrefix(strings.TrimSpace(lines[n-1]), "#")
+	}
+	isBlank := func(n int) bool {
+		return strings.TrimSpace(lines[n-1]) == ""
+	}
+
+	// Trailing blank lines and comments are left after the problems
+	for end > 1 && (len(starts) == 0 || end-1 > starts[len(starts)-1]) && (isBlank(end-1) || comments && isComment(end-1)) {
+		end--
+	}
+	if comments {
+		for i := range starts {
+			prev := 0
+			if i > 0 {
+				prev = starts[i-1]
+			}
+			for starts[i]-1 > prev && isComment(starts[i]-1) {
+				starts[i]--
+			}
+		}
+	}
+
+	join := func(from, to int) string {
+		return strings.Join(lines[from-1:to-1], "")
+	}
+	if len(starts) == 0 {
+		d.prefix, d.suffix = join(1, end), join(end, len(lines)+1)
+		return nil
+	}
+	d.prefix = join(1, starts[0])
+	for i, start := range starts {
+		next := end
+		if i+1 < len(starts) {
+			next = starts[i+1]
+		}
+		d.entries = append(d.entries, docEntry{problem: problems[i], raw: join(start, next)})
+	}
+	d.suffix = join(end, len(lines)+1)
+	return nil
+}
+
+func (d *BankDocument) splitCSV(data []byte, problems []Problem) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	var starts, fields []int
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if d.header == nil && len(starts) == 0 && isCSVHeader(row) {
+			d.header, _ = csvHeader(row)
+			continue
+		}
+		starts = append(starts, line)
+		fields = append(fields, len(row))
+	}
+	lines := splitLines(data)
+	if err := d.split(lines, problems, starts, len(lines)+1, false); err != nil {
+		return err
+	}
+	for i := range d.entries {
+		d.entries[i].fields = fields[i]
+	}
+	return nil
+}
+
+func (d *BankDocument) splitJSON(data []byte, problems []Problem) error {
+	spans, ok := jsonProblemSpans(data)
+	if !ok || len(spans.starts) != len(problems) {
+		return errors.New("can't find the problems list")
+	}
+	d.indent = "    "
+	d.prefix, d.suffix = string(data[:spans.open]), string(data[spans.close:])
+	if len(problems) == 0 {
+		// Indent the problems one step more than the line the list is on
+		lineStart := bytes.LastIndexByte(data[:spans.open], '\n') + 1
+		base := data[lineStart:spans.open]
+		base = base[:len(base)-len(bytes.TrimLeft(base, " \t"))]
+		d.indent = string(base) + "  "
+		d.lead, d.sep, d.trail = "\n"+d.indent, ",\n"+d.indent, "\n"+string(base)
+		return nil
+	}
+
+	first, last := spans.starts[0], spans.ends[len(spans.ends)-1]
+	d.lead = string(data[spans.open:first])
+	d.trail = string(data[last:spans.close])
+	if i := strings.LastIndexByte(d.lead, '\n'); i >= 0 && strings.TrimSpace(d.lead[i:]) == "" {
+		d.indent = d.lead[i+1:]
+	}
+	d.sep = "," + d.lead
+	if len(spans.starts) > 1 {
+		d.sep = string(data[spans.ends[0]:spans.starts[1]])
+	}
+	for i := range problems {
+		d.entries = append(d.entries, docEntry{problem: problems[i], raw: string(data[spans.starts[i]:spans.ends[i]])})
+	}
+	return nil
+}
+
+func (d *BankDocument) splitYAML(data []byte, problems []Problem) error {
+	list := yamlProblemList(data)
+	lines := splitLines(data)
+	if len(problems) == 0 {
+		// New problems go under the problems key, which may be missing or
+		// have an empty list
+		d.indent = "  "
+		if list.key == 0 {
+			d.prefix = string(data)
+			if len(d.prefix) > 0 && !strings.HasSuffix(d.prefix, "\n") {
+				d.prefix += "\n"
+			}
+			d.prefix += "problems:\n"
+			return nil
+		}
+		d.prefix = strings.Join(lines[:list.key-1], "") + "problems:\n"
+		d.suffix = strings.Join(lines[list.key:], "")
+		return nil
+	}
+	d.indent = strings.Repeat(" ", list.indent)
+	return d.split(lines, problems, list.items, list.end, true)
+}
+
+func (d *BankDocument) splitTOML(data []byte, problems []Problem) error {
+	lines := splitLines(data)
+	starts := tomlProblemLines(data)
+	// Any other table after the problems ends them
+	end := len(lines) + 1
+	if len(starts) > 0 {
+		for n := starts[len(starts)-1] + 1; n <= len(lines); n++ {
+			text, _, _ := strings.Cut(lines[n-1], "#")
+			text = strings.TrimSpace(text)
+			if lines[n-1][0] == '[' && strings.HasSuffix(text, "]") {
+				end = n
+				break
+			}
+		}
+	}
+	return d.split(lines, problems, starts, end, true)
+}
+
+// jsonSpans are the offsets of the problems list of a JSON bank.
+type jsonSpans struct {
+	// open is just after the "[" starting the list, and close is the "]"
+	// ending it
+	open, close int64
+	// starts and ends are where each problem starts and ends
+	starts, ends []int64
+}
+
+// jsonProblemSpans finds the problems list of a JSON bank.
+func jsonProblemSpans(data []byte) (jsonSpans, bool) {
+	var spans jsonSpans
+	d := json.NewDecoder(bytes.NewReader(data))
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return spans, false
+	}
+	for d.More() {
+		key, err := d.Token()
+		if err != nil {
+			return spans, false
+		}
+		if key != "problems" {
+			var skip json.RawMessage
+			if err := d.Decode(&skip); err != nil {
+				return spans, false
+			}
+			continue
+		}
+
+		if t, err := d.Token(); err != nil || t != json.Delim('[') {
+			return spans, false
+		}
+		spans.open = d.InputOffset()
+		for d.More() {
+			// The decoder is left before the comma and any space ahead
+			// of the next problem
+			start := skipJSONSpace(data, d.InputOffset())
+			var skip json.RawMessage
+			if err := d.Decode(&skip); err != nil {
+				return spans, false
+			}
+			spans.starts = append(spans.starts, start)
+			spans.ends = append(spans.ends, d.InputOffset())
+		}
+		if _, err := d.Token(); err != nil {
+			return spans, false
+		}
+		spans.close = d.InputOffset() - 1
+		return spans, true
+	}
+	return spans, false
+}
+
+// skipJSONSpace returns the offset of the first byte from offset which
+// isn't space or a comma.
+func skipJSONSpace(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// yamlList is where the problems list of a YAML bank is, by line
+// counting from 1.
+type yamlList struct {
+	// key is the line of the problems key, or 0 when there isn't one
+	key int
+	// items are the lines each problem starts on
+	items []int
+	// end is the line after the list
+	end    int
+	indent int
+}
+
+// yamlProblemList finds the items of the problems list of a YAML bank
+// written in block style.
+func yamlProblemList(data []byte) yamlList {
+	lines := splitLines(data)
+	list := yamlList{end: len(lines) + 1, indent: -1}
+	for i, line := range lines {
+		n := i + 1
+		line = strings.TrimRight(line, " \t\r\n")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		depth := len(line) - len(text)
+
+		if list.key == 0 {
+			if depth == 0 && strings.HasPrefix(line, "problems:") {
+				list.key = n
+			}
+			continue
+		}
+		// Another top level key ends the list
+		if depth == 0 && !strings.HasPrefix(text, "-") {
+			list.end = n
+			break
+		}
+		if text != "-" && !strings.HasPrefix(text, "- ") {
+			continue
+		}
+		if list.indent < 0 {
+			list.indent = depth
+		}
+		if depth == list.indent {
+			list.items = append(list.items, n)
+		}
+	}
+	return list
+}
@@ -0,0 +1,185 @@
+package quiz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBankDocument(t *testing.T) {
+	add := func(d *BankDocument) error { return d.Add(Problem{Question: "q4", Answer: "a4"}) }
+	edit := func(d *BankDocument) error { return d.Set(1, Problem{Question: "q2", Answer: "changed"}) }
+	move := func(d *BankDocument) error { return d.Move(0, d.Len()-1) }
+	remove := func(d *BankDocument) error { return d.Delete(0) }
+
+	const (
+		csvBank  = "q1,a1\n\nq2,\"a, 2\"\nq3,a3"
+		csvCRLF  = "question,answer\r\nq1,a1\r\nq2,a2\r\nq3,a3\r\n"
+		jsonBank = "{\n  \"problems\": [\n    {\"question\": \"q1\", \"answer\": \"a1\"},\n    {\"question\": \"q2\", \"answer\": \"a2\"},\n    {\"question\": \"q3\", \"answer\": \"a3\"}\n  ]\n}"
+		jsonCRLF = "{\r\n  \"problems\": [\r\n    {\"question\": \"q1\", \"answer\": \"a1\"},\r\n    {\"question\": \"q2\", \"answer\": \"a2\"}\r\n  ]\r\n}\r\n"
+		yamlBank = "# bank\nproblems:\n  # first\n  - question: q1\n    answer: a1\n  - question: q2 # inline\n    answer: a2\n  # third\n  - question: q3\n    answer: a3"
+		yamlCRLF = "problems:\r\n  - question: q1\r\n    answer: a1\r\n  # second\r\n  - question: q2\r\n    answer: a2\r\n"
+		tomlBank = "# bank\n\n[[problems]]\nquestion = \"q1\"\nanswer = \"a1\"\n\n# second\n[[problems]]\nquestion = \"q2\" # inline\nanswer = \"a2\"\n\n[[problems]]\nquestion = \"q3\"\nanswer = \"a3\""
+		tomlCRLF = "[[problems]]\r\nquestion = \"q1\"\r\nanswer = \"a1\"\r\n\r\n[[problems]]\r\nquestion = \"q2\"\r\nanswer = \"a2\"\r\n"
+	)
+	tests := []struct {
+		name string
+		// file is the name of the bank, which gives its format
+		file string
+		bank string
+		edit func(d *BankDocument) error
+		want string
+	}{
+		{"csv unchanged", "bank.csv", csvBank, nil, csvBank},
+		{"csv add", "bank.csv", csvBank, add, "q1,a1\n\nq2,\"a, 2\"\nq3,a3\nq4,a4\n"},
+		{"csv edit", "bank.csv", csvBank, edit, "q1,a1\n\nq2,changed\nq3,a3\n"},
+		{"csv move", "bank.csv", csvBank, move, "q2,\"a, 2\"\nq3,a3\nq1,a1\n"},
+		{"csv delete", "bank.csv", csvBank, remove, "q2,\"a, 2\"\nq3,a3\n"},
+		{"csv crlf add", "bank.csv", csvCRLF, add, "question,answer\r\nq1,a1\r\nq2,a2\r\nq3,a3\r\nq4,a4\r\n"},
+		{"csv crlf edit", "bank.csv", csvCRLF, edit, "question,answer\r\nq1,a1\r\nq2,changed\r\nq3,a3\r\n"},
+		{"csv crlf move", "bank.csv", csvCRLF, move, "question,answer\r\nq2,a2\r\nq3,a3\r\nq1,a1\r\n"},
+		{"csv crlf delete", "bank.csv", csvCRLF, remove, "question,answer\r\nq2,a2\r\nq3,a3\r\n"},
+		{
+			"csv new column", "bank.csv", csvCRLF,
+			func(d *BankDocument) error {
+				return d.Add(Problem{Question: "q4", Answer: "a4", Tags: []string{"x"}})
+			},
+			"question,answer,tags\r\nq1,a1,\r\nq2,a2,\r\nq3,a3,\r\nq4,a4,x\r\n",
+		},
+
+		{"json unchanged", "bank.json", jsonBank, nil, jsonBank},
+		{
+			"json add", "bank.json", jsonBank, add,
+			"{\n  \"problems\": [\n    {\"question\": \"q1\", \"answer\": \"a1\"},\n    {\"question\": \"q2\", \"answer\": \"a2\"},\n    {\"question\": \"q3\", \"answer\": \"a3\"},\n    {\n      \"question\": \"q4\",\n      \"answer\": \"a4\"\n    }\n  ]\n}",
+		},
+		{
+			"json edit", "bank.json", jsonBank, edit,
+			"{\n  \"problems\": [\n    {\"question\": \"q1\", \"answer\": \"a1\"},\n    {\n      \"question\": \"q2\",\n      \"answer\": \"changed\"\n    },\n    {\"question\": \"q3\", \"answer\": \"a3\"}\n  ]\n}",
+		},
+		{
+			"json move", "bank.json", jsonBank, move,
+			"{\n  \"problems\": [\n    {\"question\": \"q2\", \"answer\": \"a2\"},\n    {\"question\": \"q3\", \"answer\": \"a3\"},\n    {\"question\": \"q1\", \"answer\": \"a1\"}\n  ]\n}",
+		},
+		{
+			"json delete", "bank.json", jsonBank, remove,
+			"{\n  \"problems\": [\n    {\"question\": \"q2\", \"answer\": \"a2\"},\n    {\"question\": \"q3\", \"answer\": \"a3\"}\n  ]\n}",
+		},
+		{
+			"json crlf add", "bank.json", jsonCRLF, add,
+			"{\r\n  \"problems\": [\r\n    {\"question\": \"q1\", \"answer\": \"a1\"},\r\n    {\"question\": \"q2\", \"answer\": \"a2\"},\r\n    {\r\n      \"question\": \"q4\",\r\n      \"answer\": \"a4\"\r\n    }\r\n  ]\r\n}\r\n",
+		},
+		{
+			"json crlf move", "bank.json", jsonCRLF, move,
+			"{\r\n  \"problems\": [\r\n    {\"question\": \"q2\", \"answer\": \"a2\"},\r\n    {\"question\": \"q1\", \"answer\": \"a1\"}\r\n  ]\r\n}\r\n",
+		},
+
+		{"yaml unchanged", "bank.yaml", yamlBank, nil, yamlBank},
+		{
+			"yaml add", "bank.yaml", yamlBank, add,
+			"# bank\nproblems:\n  # first\n  - question: q1\n    answer: a1\n  - question: q2 # inline\n    answer: a2\n  # third\n  - question: q3\n    answer: a3\n  - question: q4\n    answer: a4\n",
+		},
+		{
+			"yaml edit", "bank.yaml", yamlBank, edit,
+			"# bank\nproblems:\n  # first\n  - question: q1\n    answer: a1\n  - question: q2\n    answer: changed\n  # third\n  - question: q3\n    answer: a3\n",
+		},
+		{
+			"yaml move", "bank.yaml", yamlBank, move,
+			"# bank\nproblems:\n  - question: q2 # inline\n    answer: a2\n  # third\n  - question: q3\n    answer: a3\n  # first\n  - question: q1\n    answer: a1\n",
+		},
+		{
+			"yaml delete", "bank.yaml", yamlBank, remove,
+			"# bank\nproblems:\n  - question: q2 # inline\n    answer: a2\n  # third\n  - question: q3\n    answer: a3\n",
+		},
+		{
+			"yaml crlf add", "bank.yaml", yamlCRLF, add,
+			"problems:\r\n  - question: q1\r\n    answer: a1\r\n  # second\r\n  - question: q2\r\n    answer: a2\r\n  - question: q4\r\n    answer: a4\r\n",
+		},
+		{
+			"yaml crlf move", "bank.yaml", yamlCRLF, move,
+			"problems:\r\n  # second\r\n  - question: q2\r\n    answer: a2\r\n  - question: q1\r\n    answer: a1\r\n",
+		},
+
+		{"toml unchanged", "bank.toml", tomlBank, nil, tomlBank},
+		{
+			"toml add", "bank.toml", tomlBank, add,
+			"# bank\n\n[[problems]]\nquestion = \"q1\"\nanswer = \"a1\"\n\n# second\n[[problems]]\nquestion = \"q2\" # inline\nanswer = \"a2\"\n\n[[problems]]\nquestion = \"q3\"\nanswer = \"a3\"\n\n[[problems]]\nquestion = \"q4\"\nanswer = \"a4\"\n",
+		},
+		{
+			"toml edit", "bank.toml", tomlBank, edit,
+			"# bank\n\n[[problems]]\nquestion = \"q1\"\nanswer = \"a1\"\n\n[[problems]]\nquestion = \"q2\"\nanswer = \"changed\"\n\n[[problems]]\nquestion = \"q3\"\nanswer = \"a3\"\n",
+		},
+		{
+			"toml move", "bank.toml", tomlBank, move,
+			"# bank\n\n# second\n[[problems]]\nquestion = \"q2\" # inline\nanswer = \"a2\"\n\n[[problems]]\nquestion = \"q3\"\nanswer = \"a3\"\n\n[[problems]]\nquestion = \"q1\"\nanswer = \"a1\"\n",
+		},
+		{
+			"toml delete", "bank.toml", tomlBank, remove,
+			"# bank\n\n# second\n[[problems]]\nquestion = \"q2\" # inline\nanswer = \"a2\"\n\n[[problems]]\nquestion = \"q3\"\nanswer = \"a3\"\n",
+		},
+		{
+			"toml crlf add", "bank.toml", tomlCRLF, add,
+			"[[problems]]\r\nquestion = \"q1\"\r\nanswer = \"a1\"\r\n\r\n[[problems]]\r\nquestion = \"q2\"\r\nanswer = \"a2\"\r\n\r\n[[problems]]\r\nquestion = \"q4\"\r\nanswer = \"a4\"\r\n",
+		},
+		{
+			"toml crlf move", "bank.toml", tomlCRLF, move,
+			"[[problems]]\r\nquestion = \"q2\"\r\nanswer = \"a2\"\r\n\r\n[[problems]]\r\nquestion = \"q1\"\r\nanswer = \"a1\"\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.bank), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			d, err := OpenBankDocument(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.edit != nil {
+				if err := tt.edit(d); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := d.Save(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("saved bank:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBankDocumentNew(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"bank.csv", "q1,a1\n"},
+		{"bank.json", "{\n  \"problems\": [\n    {\n      \"question\": \"q1\",\n      \"answer\": \"a1\"\n    }\n  ]\n}\n"},
+		{"bank.yaml", "problems:\n  - question: q1\n    answer: a1\n"},
+		{"bank.toml", "[[problems]]\nquestion = \"q1\"\nanswer = \"a1\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			d, err := OpenBankDocument(filepath.Join(t.TempDir(), tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Add(Problem{Question: "q1", Answer: "a1"}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := d.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Bytes() =\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
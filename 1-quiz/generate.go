@@ -0,0 +1,223 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// GeneratorSpec describes the arithmetic problems made by Generate.
+type GeneratorSpec struct {
+	// Operators are the operators to pick from, out of "+", "-", "*" and
+	// "/".
+	Operators []string
+	// Min and Max are the range of the operands, inclusive
+	Min, Max int
+	// Count is the number of problems to make
+	Count int
+	// AllowNegative lets answers be negative. When false, operands are
+	// swapped as needed so that subtraction doesn't go below zero.
+	AllowNegative bool
+	// Decimals is the number of decimal places in the operands
+	Decimals int
+}
+
+// DefaultGeneratorSpec is used for anything not set in a spec passed to
+// ParseGeneratorSpec.
+var DefaultGeneratorSpec = GeneratorSpec{
+	Operators: []string{"+"},
+	Min:       0,
+	Max:       10,
+	Count:     10,
+}
+
+// ParseGeneratorSpec parses a comma separated list of settings, such as
+//
+//	ops=+-*/,min=1,max=12,count=20,negatives,decimals=1
+//
+// Settings that are left out keep their value from DefaultGeneratorSpec.
+func ParseGeneratorSpec(s string) (GeneratorSpec, error) {
+	spec := DefaultGeneratorSpec
+	for _, setting := range strings.Split(s, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(setting, "=")
+
+		var err error
+		switch strings.ToLower(key) {
+		case "ops":
+			spec.Operators = nil
+			for _, op := range value {
+				spec.Operators = append(spec.Operators, string(op))
+			}
+		case "min":
+			spec.Min, err = strconv.Atoi(value)
+		case "max":
+			spec.Max, err = strconv.Atoi(value)
+		case "count":
+			spec.Count, err = strconv.Atoi(value)
+		case "decimals":
+			spec.Decimals, err = strconv.Atoi(value)
+		case "negatives":
+			spec.AllowNegative = true
+			if value != "" {
+				spec.AllowNegative, err = strconv.ParseBool(value)
+			}
+		default:
+			return spec, fmt.Errorf("unknown generator setting %q", key)
+		}
+		if err != nil {
+			return spec, fmt.Errorf("invalid value for %s: %q", key, value)
+		}
+	}
+	return spec, spec.validate()
+}
+
+// maxOperand is the largest an operand can be when it is counted in units
+// of its last decimal place, so that the product of two operands fits in
+// an int.
+const maxOperand = 1000000000
+
+func (s GeneratorSpec) validate() error {
+	if len(s.Operators) == 0 {
+		return errors.New("no operators to generate problems with")
+	}
+	for _, op := range s.Operators {
+		if !strings.Contains("+-*/", op) {
+			return fmt.Errorf("unknown operator %q", op)
+		}
+	}
+	if s.Decimals < 0 || s.Decimals > 6 {
+		return errors.New("decimals must be between 0 and 6")
+	}
+	if limit := maxOperand / decimalScale(s.Decimals); s.Min < -limit || s.Max > limit {
+		return fmt.Errorf("min and max must be between %d and %d with %d decimals", -limit, limit, s.Decimals)
+	}
+	if s.Min > s.Max {
+		return fmt.Errorf("min %d is greater than max %d", s.Min, s.Max)
+	}
+	if s.Min < 0 && !s.AllowNegative {
+		return errors.New("negative operands need negatives to be allowed")
+	}
+	if s.Count <= 0 {
+		return errors.New("count must be positive")
+	}
+	for _, op := range s.Operators {
+		if op == "/" && s.Min == 0 && s.Max == 0 {
+			return errors.New("division needs a non-zero operand range")
+		}
+	}
+	return nil
+}
+
+// Generate makes arithmetic problems following spec. The answers are
+// worked out exactly and checked with a Numeric matcher, so "2.50" is
+// accepted for "2.5". Using the same seed for rnd gives the same problems.
+func Generate(spec GeneratorSpec, rnd *rand.Rand) ([]Problem, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	// Work in whole numbers of the smallest decimal place so that answers
+	// are exact, e.g. 1.5 is 15 when there is one decimal place
+	scale := decimalScale(spec.Decimals)
+	operand := func() int {
+		return spec.Min*scale + rnd.Intn((spec.Max-spec.Min)*scale+1)
+	}
+	nonZero := func() int {
+		for {
+			if v := operand(); v != 0 {
+				return v
+			}
+		}
+	}
+
+	problems := make([]Problem, spec.Count)
+	for i := range problems {
+		op := spec.Operators[rnd.Intn(len(spec.Operators))]
+
+		// Multiplying two scaled numbers doubles the number of decimal
+		// places, so the dividend and products are written with more
+		var a, b, answer int
+		aPlaces, answerPlaces := spec.Decimals, spec.Decimals
+		switch op {
+		case "+":
+			a, b = operand(), operand()
+			answer = a + b
+		case "-":
+			a, b = operand(), operand()
+			if !spec.AllowNegative && a < b {
+				a, b = b, a
+			}
+			answer = a - b
+		case "*":
+			a, b = operand(), operand()
+			answer = a * b
+			answerPlaces *= 2
+		case "/":
+			// Build the dividend from the divisor and answer so that the
+			// division comes out exactly
+			b, answer = nonZero(), operand()
+			a = b * answer
+			aPlaces *= 2
+		}
+
+		problems[i] = Problem{
+			Question: fmt.Sprintf(
+				"%s%s%s",
+				formatOperand(a, aPlaces),
+				op,
+				formatOperand(b, spec.Decimals),
+			),
+			Answer:  formatDecimal(answer, answerPlaces),
+			Tags:    []string{"generated"},
+			Matcher: Numeric{Tolerance: 1e-9},
+		}
+	}
+	return problems, nil
+}
+
+// decimalScale returns 10 to the power of decimals, which is how many units
+// of the last decimal place make one.
+func decimalScale(decimals int) int {
+	scale := 1
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	return scale
+}
+
+// formatDecimal writes v, which counts in units of the given number of
+// decimal places, without trailing zeros.
+func formatDecimal(v int, places int) string {
+	s := strconv.Itoa(v)
+	if places == 0 {
+		return s
+	}
+
+	sign := ""
+	if v < 0 {
+		sign, s = "-", s[1:]
+	}
+	for len(s) <= places {
+		s = "0" + s
+	}
+	whole, frac := s[:len(s)-places], strings.TrimRight(s[len(s)-places:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// formatOperand is formatDecimal with negative numbers in brackets, so
+// that questions read "5-(-3)".
+func formatOperand(v int, places int) string {
+	if v < 0 {
+		return "(" + formatDecimal(v, places) + ")"
+	}
+	return formatDecimal(v, places)
+}
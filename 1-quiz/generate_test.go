@@ -0,0 +1,97 @@
+package quiz
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParseGeneratorSpecLimits(t *testing.T) {
+	tests := []struct {
+		spec string
+		// err is part of the error expected, or empty when the spec is
+		// valid
+		err string
+	}{
+		{"min=0,max=1000000000", ""},
+		{"min=-1000000000,max=0,negatives", ""},
+		{"min=0,max=1000,decimals=6", ""},
+		{"min=0,max=9223372036854775807", "min and max must be between"},
+		{"min=-9223372036854775808,max=0,negatives", "min and max must be between"},
+		{"min=0,max=1001,decimals=6", "min and max must be between -1000 and 1000 with 6 decimals"},
+		{"decimals=7", "decimals must be between 0 and 6"},
+		{"decimals=-1", "decimals must be between 0 and 6"},
+		{"min=5,max=1", "min 5 is greater than max 1"},
+	}
+	for _, tt := range tests {
+		_, err := ParseGeneratorSpec(tt.spec)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("ParseGeneratorSpec(%q) error = %v", tt.spec, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("ParseGeneratorSpec(%q) error = %v, want one containing %q", tt.spec, err, tt.err)
+		}
+	}
+}
+
+func TestGenerateAtLimits(t *testing.T) {
+	// The largest operands allowed must not overflow, even when they are
+	// multiplied or divided
+	for _, s := range []string{
+		"ops=+-*/,min=-1000000000,max=1000000000,negatives,count=200",
+		"ops=*/,min=-1000,max=1000,negatives,decimals=6,count=200",
+	} {
+		spec, err := ParseGeneratorSpec(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		problems, err := Generate(spec, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatalf("Generate(%q) error = %v", s, err)
+		}
+		for _, p := range problems {
+			if want := solve(t, p.Question); want.Cmp(rat(t, p.Answer)) != 0 {
+				t.Errorf("%s = %s, want %s", p.Question, p.Answer, want.FloatString(12))
+			}
+		}
+	}
+}
+
+// solve works out a generated question such as "1.5*(-2)" exactly.
+func solve(t *testing.T, question string) *big.Rat {
+	t.Helper()
+	// Negative operands are in brackets, so the operator is the first one
+	// outside of them after the first character
+	depth := 0
+	for i, r := range question {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case i > 0 && depth == 0 && strings.ContainsRune("+-*/", r):
+			a, b := rat(t, question[:i]), rat(t, question[i+1:])
+			switch r {
+			case '+':
+				return a.Add(a, b)
+			case '-':
+				return a.Sub(a, b)
+			case '*':
+				return a.Mul(a, b)
+			}
+			return a.Quo(a, b)
+		}
+	}
+	t.Fatalf("no operator in %q", question)
+	return nil
+}
+
+func rat(t *testing.T, s string) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(strings.Trim(s, "()"))
+	if !ok {
+		t.Fatalf("%q is not a number", s)
+	}
+	return r
+}
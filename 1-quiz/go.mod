@@ -1,3 +1,17 @@
 module github.com/julianchong00/quiz
 
 go 1.19
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/gorilla/websocket v1.5.0
+	golang.org/x/crypto v0.11.0
+	golang.org/x/term v0.10.0
+	golang.org/x/text v0.13.0
+)
+
+require golang.org/x/sys v0.10.0 // indirect
@@ -0,0 +1,66 @@
+package quiz
+
+import "io"
+
+// HintCommand is typed instead of an answer to reveal the next hint for
+// the problem.
+const HintCommand = "?"
+
+// DefaultHintPenalty is the fraction of a problem's points lost for each
+// hint revealed.
+const DefaultHintPenalty = 0.25
+
+// WithHintPenalty sets the fraction of a problem's points lost for each
+// hint revealed. A problem can't score less than zero however many hints
+// are used.
+func WithHintPenalty(penalty float64) Option {
+	return func(q *Quiz) {
+		q.hintPenalty = penalty
+	}
+}
+
+// WithPractice tells the user whether they were right after every answer,
+// along with the explanation for any problem they missed.
+func WithPractice() Option {
+	return func(q *Quiz) {
+		q.practice = true
+	}
+}
+
+// score returns the points for an answer with the given credit, less the
+// penalty for the hints used.
+func (q *Quiz) score(p Problem, credit float64, hints int) float64 {
+	penalty := 1 - q.hintPenalty*float64(hints)
+	if penalty < 0 {
+		penalty = 0
+	}
+	return p.Worth() * credit * penalty
+}
+
+// maxPoints returns what the quiz was worth. That is every problem when
+// the strategy asks all of them, and otherwise the problems which were
+// asked along with an average problem for each one which wasn't.
+func (q *Quiz) maxPoints(r Result) float64 {
+	all := 0.0
+	for _, p := range q.problems {
+		all += p.Worth()
+	}
+	if q.strategy.Len() >= len(q.problems) {
+		return all
+	}
+
+	max := 0.0
+	for _, a := range r.Answers {
+		max += a.Problem.Worth()
+	}
+	if left := r.Total - len(r.Answers); left > 0 {
+		max += float64(left) * all / float64(len(q.problems))
+	}
+	return max
+}
+
+// WriteMissed lists the problems in the result which were missed in
+// English. See Messages.WriteMissed.
+func WriteMissed(w io.Writer, r Result) {
+	English.WriteMissed(w, r)
+}
@@ -0,0 +1,235 @@
+package quiz
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Record is a finished quiz run as it is kept in the history.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Bank   string    `json:"bank"`
+	User   string    `json:"user"`
+	Total  int       `json:"total"`
+	Points float64   `json:"points"`
+	// MaxPoints is missing from records made before problems had weights,
+	// when every problem was worth one point
+	MaxPoints float64 `json:"max_points,omitempty"`
+	TimedOut  bool    `json:"timed_out"`
+	// Ended is why the quiz ended before every problem was asked. It is
+	// missing from records made before it was kept, which only ended early
+	// when TimedOut is set.
+	Ended   EndReason      `json:"ended,omitempty"`
+	Answers []RecordAnswer `json:"answers"`
+}
+
+// RecordAnswer is an Answer as it is kept in the history. Expected and
+// Given both use the letters of multiple choice options as they were
+// shown, which may not be their order in the bank. The expected answer to
+// a sealed problem is recorded as "(sealed)" rather than as its hash or
+// ciphertext.
+type RecordAnswer struct {
+	ID       string  `json:"id,omitempty"`
+	Question string  `json:"question"`
+	Expected string  `json:"expected"`
+	Given    string  `json:"given"`
+	Correct  bool    `json:"correct"`
+	Credit   float64 `json:"credit"`
+	Points   float64 `json:"points"`
+	Hints    int     `json:"hints,omitempty"`
+	// LatencyMS is the time taken to answer in milliseconds
+	LatencyMS int64 `json:"latency_ms"`
+	TimedOut  bool  `json:"timed_out"`
+	// Source is where the problem was loaded from
+	Source string `json:"source,omitempty"`
+	// Reversed is set when the problem was asked in reverse, with the
+	// answer in the bank as the question
+	Reversed bool `json:"reversed,omitempty"`
+	// KeystrokesMS are the milliseconds between the keys pressed for the
+	// answer, when they were recorded
+	KeystrokesMS []int64 `json:"keystrokes_ms,omitempty"`
+	Pasted       bool    `json:"pasted,omitempty"`
+}
+
+// Keystrokes returns the times between the keys pressed for the answer.
+func (a RecordAnswer) Keystrokes() []time.Duration {
+	var keys []time.Duration
+	for _, ms := range a.KeystrokesMS {
+		keys = append(keys, time.Duration(ms)*time.Millisecond)
+	}
+	return keys
+}
+
+// Latency returns the time taken to answer.
+func (a RecordAnswer) Latency() time.Duration {
+	return time.Duration(a.LatencyMS) * time.Millisecond
+}
+
+// Key identifies the problem the answer was for, using its ID when it has
+// one. Answers to reversed problems have keys of their own, as with
+// Problem.Key.
+func (a RecordAnswer) Key() string {
+	if a.Reversed {
+		return reverseKey(a.ID, a.Expected)
+	}
+	if a.ID != "" {
+		return a.ID
+	}
+	return a.Question
+}
+
+// NewRecord converts the result of a quiz run into a Record.
+func NewRecord(result Result, bank, user string, t time.Time) Record {
+	rec := Record{
+		Time:      t,
+		Bank:      bank,
+		User:      user,
+		Total:     result.Total,
+		Points:    result.Points(),
+		MaxPoints: result.MaxPoints,
+		TimedOut:  result.TimedOut,
+		Ended:     result.Ended,
+		Answers:   make([]RecordAnswer, len(result.Answers)),
+	}
+	for i, a := range result.Answers {
+		rec.Answers[i] = newRecordAnswer(a)
+	}
+	return rec
+}
+
+func newRecordAnswer(a Answer) RecordAnswer {
+	rec := RecordAnswer{
+		ID:        a.Problem.ID,
+		Question:  a.Problem.Question,
+		Expected:  recordedExpected(a),
+		Given:     a.Given,
+		Correct:   a.Correct,
+		Credit:    a.Credit,
+		Points:    a.Points,
+		Hints:     a.HintsUsed,
+		LatencyMS: a.Latency.Milliseconds(),
+		TimedOut:  a.TimedOut,
+		Source:    a.Problem.Source,
+		Reversed:  a.Problem.Reversed,
+		Pasted:    a.Pasted,
+	}
+	for _, k := range a.Keystrokes {
+		rec.KeystrokesMS = append(rec.KeystrokesMS, k.Milliseconds())
+	}
+	return rec
+}
+
+// EndReason returns why the quiz ended before every problem was asked, or
+// empty when it isn't known.
+func (r Record) EndReason() EndReason {
+	if r.Ended == "" && r.TimedOut {
+		return EndTimeUp
+	}
+	return r.Ended
+}
+
+// Max returns the points the quiz was worth.
+func (r Record) Max() float64 {
+	if r.MaxPoints > 0 {
+		return r.MaxPoints
+	}
+	return float64(r.Total)
+}
+
+// Accuracy returns the fraction of the points for the quiz which were
+// scored.
+func (r Record) Accuracy() float64 {
+	if r.Max() == 0 {
+		return 0
+	}
+	return r.Points / r.Max()
+}
+
+// Pasted returns how many answers arrived faster than they could have been
+// typed.
+func (r Record) Pasted() int {
+	n := 0
+	for _, a := range r.Answers {
+		if a.Pasted {
+			n++
+		}
+	}
+	return n
+}
+
+// History is a file of quiz records with one JSON record per line.
+type History struct {
+	path string
+}
+
+// OpenHistory returns the history kept in the file at path. The file is
+// created when the first record is added.
+func OpenHistory(path string) *History {
+	return &History{path: path}
+}
+
+// Path returns the file the history is kept in.
+func (h *History) Path() string {
+	return h.path
+}
+
+// Add appends a record to the history.
+func (h *History) Add(rec Record) error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records returns every record in the history, oldest first. A history
+// which hasn't been written to yet has no records.
+func (h *History) Records() ([]Record, error) {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	// Records for long quizzes can be bigger than the default line limit
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", h.path, line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
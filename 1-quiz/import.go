@@ -0,0 +1,293 @@
+package quiz
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ImportOptions configure how flashcards exported from other tools are
+// turned into problems.
+type ImportOptions struct {
+	// FieldDelimiter separates the front of a card from its back. The
+	// importer's own default, or what the export says, is used when it
+	// is empty.
+	FieldDelimiter string
+	// CardDelimiter separates the cards of exports which don't put one
+	// card on each line
+	CardDelimiter string
+	// KeepHTML leaves HTML in the fields of cards instead of reducing
+	// them to plain text
+	KeepHTML bool
+	// Tags are added to every imported problem
+	Tags []string
+}
+
+// Importer reads flashcards exported from another tool as problems, with
+// the front of each card as the question and the back as the answer.
+// Cards which can't be imported are skipped, and they and anything else
+// lost along the way, such as images, are returned as issues.
+type Importer func(r io.Reader, opts ImportOptions) ([]Problem, []Issue, error)
+
+// importers maps the names of the tools flashcards can be imported from
+// to their Importer.
+var importers = map[string]Importer{
+	"anki":    ImportAnki,
+	"quizlet": ImportQuizlet,
+}
+
+// RegisterImporter makes ImporterFor return the importer for format,
+// replacing any importer already registered for it.
+func RegisterImporter(format string, im Importer) {
+	importers[strings.ToLower(format)] = im
+}
+
+// ImportFormats returns the names of every registered import format.
+func ImportFormats() []string {
+	formats := make([]string, 0, len(importers))
+	for f := range importers {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ImporterFor returns the importer registered for the format.
+func ImporterFor(format string) (Importer, error) {
+	im, ok := importers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q, expected one of %s", format, strings.Join(ImportFormats(), ", "))
+	}
+	return im, nil
+}
+
+// ankiSeparators are the names Anki uses for separators in the header of
+// its text exports.
+var ankiSeparators = map[string]string{
+	"tab":       "\t",
+	"comma":     ",",
+	"semicolon": ";",
+	"space":     " ",
+	"pipe":      "|",
+	"colon":     ":",
+}
+
+// ImportAnki reads the "Notes in Plain Text" export of Anki. Each line is
+// a note with its fields separated by tabs, or by the separator named in
+// a "#separator:" header, and fields holding line breaks are quoted. The
+// first field is the question and the second the answer. The tags and
+// guid columns named in the "#tags column:" and "#guid column:" headers
+// become the tags and ID of the problem. Fields are read as HTML unless an
+// "#html:false" header says they are plain text.
+func ImportAnki(r io.Reader, opts ImportOptions) ([]Problem, []Issue, error) {
+	in := bufio.NewReader(r)
+	// The headers are lines starting with "#" ahead of the notes
+	sep, plain := "\t", false
+	meta := make(map[int]string)
+	line := 0
+	for {
+		b, err := in.Peek(1)
+		if err != nil || b[0] != '#' {
+			break
+		}
+		header, err := in.ReadString('\n')
+		line++
+		key, value, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(header, "#")), ":")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "separator":
+			if s, ok := ankiSeparators[strings.ToLower(value)]; ok {
+				sep = s
+			} else {
+				sep = value
+			}
+		case "html":
+			plain = strings.EqualFold(value, "false")
+		case "tags column", "guid column", "deck column", "notetype column":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, nil, fmt.Errorf("line %d: invalid %s %q", line, key, value)
+			}
+			meta[n-1] = strings.TrimSuffix(key, " column")
+		}
+		if err != nil {
+			break
+		}
+	}
+	if opts.FieldDelimiter != "" {
+		sep = opts.FieldDelimiter
+	}
+	comma, size := utf8.DecodeRuneInString(sep)
+	if size != len(sep) || comma == '"' || comma == '\r' || comma == '\n' {
+		return nil, nil, fmt.Errorf("the field delimiter of Anki exports must be a single character, got %q", sep)
+	}
+
+	reader := csv.NewReader(in)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var problems []Problem
+	var issues []Issue
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			issues = append(issues, Issue{Line: line + parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row, _ := reader.FieldPos(0)
+		row += line
+
+		var card []string
+		var p Problem
+		for i, field := range fields {
+			switch meta[i] {
+			case "tags":
+				p.Tags = strings.Fields(field)
+			case "guid":
+				p.ID = strings.TrimSpace(field)
+			case "":
+				card = append(card, field)
+			}
+		}
+		if len(card) < 2 {
+			issues = append(issues, Issue{Line: row, Message: "skipped a note without both a front and a back"})
+			continue
+		}
+		if p, ok := importCard(p, card[0], card[1], row, plain, opts, &issues); ok {
+			problems = append(problems, p)
+		}
+	}
+	return problems, issues, nil
+}
+
+// ImportQuizlet reads a Quizlet export, which puts each term and its
+// definition on a line separated by a tab unless other delimiters were
+// picked for the export. The term is the question and the definition the
+// answer.
+func ImportQuizlet(r io.Reader, opts ImportOptions) ([]Problem, []Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldSep, cardSep := opts.FieldDelimiter, opts.CardDelimiter
+	if fieldSep == "" {
+		fieldSep = "\t"
+	}
+	if cardSep == "" {
+		cardSep = "\n"
+	}
+
+	var problems []Problem
+	var issues []Issue
+	offset := 0
+	for _, card := range strings.Split(string(data), cardSep) {
+		row := lineAt(data, int64(offset))
+		offset += len(card) + len(cardSep)
+		// Delimiters other than new lines are often followed by one
+		if cardSep != "\n" {
+			trimmed := strings.TrimLeft(card, "\r\n")
+			row += strings.Count(card[:len(card)-len(trimmed)], "\n")
+			card = trimmed
+		}
+		card = strings.TrimRight(card, "\r\n")
+		if strings.TrimSpace(card) == "" {
+			continue
+		}
+
+		term, definition, ok := strings.Cut(card, fieldSep)
+		if !ok {
+			issues = append(issues, Issue{Line: row, Message: fmt.Sprintf("skipped a card without the delimiter %q between its term and definition", fieldSep)})
+			continue
+		}
+		if p, ok := importCard(Problem{}, term, definition, row, false, opts, &issues); ok {
+			problems = append(problems, p)
+		}
+	}
+	return problems, issues, nil
+}
+
+// importCard turns the front and back of a card into a problem, recording
+// anything which was dropped as an issue. plain is set when the fields are
+// plain text rather than HTML. It reports false when the card is left
+// without a question or answer.
+func importCard(p Problem, front, back string, line int, plain bool, opts ImportOptions, issues *[]Issue) (Problem, bool) {
+	var dropped []string
+	if opts.KeepHTML {
+		// Problems are asked on a single line
+		front, back = strings.Join(strings.Fields(front), " "), strings.Join(strings.Fields(back), " ")
+	} else {
+		front, dropped = cardText(front, plain, dropped)
+		back, dropped = cardText(back, plain, dropped)
+	}
+	for _, media := range dropped {
+		*issues = append(*issues, Issue{Line: line, Message: "dropped " + media})
+	}
+
+	p.Question, p.Answer = front, back
+	if p.Question == "" || p.Answer == "" {
+		*issues = append(*issues, Issue{Line: line, Message: "skipped a card which has no text on one side"})
+		return p, false
+	}
+	for _, tag := range opts.Tags {
+		if !contains(p.Tags, tag) {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+	return p, true
+}
+
+var (
+	// ankiSound is how Anki refers to audio in a field
+	ankiSound = regexp.MustCompile(`\[sound:([^\]]*)\]`)
+	htmlMedia = regexp.MustCompile(`(?i)<(img|audio|video|source|object|embed)\b[^>]*>`)
+	htmlSrc   = regexp.MustCompile(`(?i)\bsrc\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	// htmlBreak are the tags which start a new line
+	htmlBreak = regexp.MustCompile(`(?i)<\s*(br|/?p|/?div|/?li)\b[^>]*>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// cardText reduces a field of a card to plain text on a single line,
+// adding a description of each image and sound it refers to to dropped.
+// Fields which are already plain text only lose their sounds, so that
+// text such as "a <b" is left as it is.
+func cardText(s string, plain bool, dropped []string) (string, []string) {
+	for _, m := range ankiSound.FindAllStringSubmatch(s, -1) {
+		dropped = append(dropped, "sound "+m[1])
+	}
+	s = ankiSound.ReplaceAllString(s, " ")
+	if plain {
+		return strings.Join(strings.Fields(s), " "), dropped
+	}
+	for _, tag := range htmlMedia.FindAllStringSubmatch(s, -1) {
+		kind := strings.ToLower(tag[1])
+		if kind == "img" {
+			kind = "image"
+		}
+		if src := htmlSrc.FindStringSubmatch(tag[0]); src != nil {
+			kind += " " + strings.Trim(src[1], `"'`)
+		}
+		dropped = append(dropped, kind)
+	}
+	s = htmlMedia.ReplaceAllString(s, " ")
+	s = htmlBreak.ReplaceAllString(s, " ")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	// Non-breaking spaces are common in exported HTML
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	return strings.Join(strings.Fields(s), " "), dropped
+}
@@ -0,0 +1,218 @@
+package quiz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportAnki(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+		opts   ImportOptions
+		want   []Problem
+		issues []Issue
+	}{
+		{
+			name:   "tabs",
+			export: "#separator:tab\n#html:true\n5+5\t10\ncapital of France\tParis\n",
+			want:   []Problem{{Question: "5+5", Answer: "10"}, {Question: "capital of France", Answer: "Paris"}},
+		},
+		{
+			name:   "separator and columns",
+			export: "#separator:Semicolon\n#html:true\n#guid column:1\n#tags column:4\nid1;What is 2+2?;4;maths easy\n",
+			want:   []Problem{{ID: "id1", Question: "What is 2+2?", Answer: "4", Tags: []string{"maths", "easy"}}},
+		},
+		{
+			name:   "literal separator",
+			export: "#separator:|\nfront|back\n",
+			want:   []Problem{{Question: "front", Answer: "back"}},
+		},
+		{
+			name:   "quoted multi-line fields",
+			export: "#separator:tab\n#html:true\n\"line one\nline two\"\t\"a\tb\"\nq2\ta2 [sound:q2.mp3]\n",
+			want:   []Problem{{Question: "line one line two", Answer: "a b"}, {Question: "q2", Answer: "a2"}},
+			issues: []Issue{{Line: 5, Message: "dropped sound q2.mp3"}},
+		},
+		{
+			name:   "html",
+			export: "#html:true\n<div>Capital of <i>France</i></div>\tParis&nbsp;<br>city &amp; port\n",
+			want:   []Problem{{Question: "Capital of France", Answer: "Paris city & port"}},
+		},
+		{
+			name: "media",
+			export: "#html:true\n" +
+				"5+5\t10\n" +
+				"Where is this? <img src=\"paris.jpg\">\tParis [sound:paris.mp3]\n" +
+				"<img src='only.png'>\tnothing\n",
+			want: []Problem{{Question: "5+5", Answer: "10"}, {Question: "Where is this?", Answer: "Paris"}},
+			issues: []Issue{
+				{Line: 3, Message: "dropped image paris.jpg"},
+				{Line: 3, Message: "dropped sound paris.mp3"},
+				{Line: 4, Message: "dropped image only.png"},
+				{Line: 4, Message: "skipped a card which has no text on one side"},
+			},
+		},
+		{
+			name:   "plain text is left alone",
+			export: "#html:false\nis a <b true?\tyes &amp; no [sound:x.mp3]\n",
+			want:   []Problem{{Question: "is a <b true?", Answer: "yes &amp; no"}},
+			issues: []Issue{{Line: 2, Message: "dropped sound x.mp3"}},
+		},
+		{
+			name:   "keep html",
+			export: "#html:true\n<b>bold</b>\t<i>it</i>\n",
+			opts:   ImportOptions{KeepHTML: true},
+			want:   []Problem{{Question: "<b>bold</b>", Answer: "<i>it</i>"}},
+		},
+		{
+			name:   "keep html of plain text",
+			export: "#html:false\na <b\t  c\n",
+			opts:   ImportOptions{KeepHTML: true},
+			want:   []Problem{{Question: "a <b", Answer: "c"}},
+		},
+		{
+			name:   "without html header",
+			export: "<b>bold</b>\tx\n",
+			want:   []Problem{{Question: "bold", Answer: "x"}},
+		},
+		{
+			name:   "missing back",
+			export: "#separator:tab\nfront only\nq\ta\n",
+			want:   []Problem{{Question: "q", Answer: "a"}},
+			issues: []Issue{{Line: 2, Message: "skipped a note without both a front and a back"}},
+		},
+		{
+			name:   "field delimiter option",
+			export: "#separator:tab\nq,a\n",
+			opts:   ImportOptions{FieldDelimiter: ","},
+			want:   []Problem{{Question: "q", Answer: "a"}},
+		},
+		{
+			name:   "extra tags",
+			export: "#tags column:3\nq\ta\tmaths\n",
+			opts:   ImportOptions{Tags: []string{"anki", "maths"}},
+			want:   []Problem{{Question: "q", Answer: "a", Tags: []string{"maths", "anki"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, issues, err := ImportAnki(strings.NewReader(tt.export), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problems, tt.want) {
+				t.Errorf("problems =\n%#v\nwant\n%#v", problems, tt.want)
+			}
+			if !reflect.DeepEqual(issues, tt.issues) {
+				t.Errorf("issues =\n%#v\nwant\n%#v", issues, tt.issues)
+			}
+		})
+	}
+}
+
+func TestImportAnkiErrors(t *testing.T) {
+	tests := []struct {
+		export string
+		opts   ImportOptions
+		err    string
+	}{
+		{"#tags column:x\nq\ta\n", ImportOptions{}, `line 1: invalid tags column "x"`},
+		{"#separator:tab\n#guid column:0\n", ImportOptions{}, `line 2: invalid guid column "0"`},
+		{"#separator:--\nq--a\n", ImportOptions{}, "must be a single character"},
+		{"q\ta\n", ImportOptions{FieldDelimiter: "\""}, "must be a single character"},
+	}
+	for _, tt := range tests {
+		_, _, err := ImportAnki(strings.NewReader(tt.export), tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ImportAnki(%q) error = %v, want one containing %q", tt.export, err, tt.err)
+		}
+	}
+}
+
+func TestImportQuizlet(t *testing.T) {
+	tests := []struct {
+		name   string
+		export string
+		opts   ImportOptions
+		want   []Problem
+		issues []Issue
+	}{
+		{
+			name:   "tabs",
+			export: "sun\tsoleil\r\nmoon\tlune\n\n",
+			want:   []Problem{{Question: "sun", Answer: "soleil"}, {Question: "moon", Answer: "lune"}},
+		},
+		{
+			name:   "custom delimiters",
+			export: "sun - soleil;\nmoon - lune;\nstar;\nsky - ciel",
+			opts:   ImportOptions{FieldDelimiter: " - ", CardDelimiter: ";"},
+			want:   []Problem{{Question: "sun", Answer: "soleil"}, {Question: "moon", Answer: "lune"}, {Question: "sky", Answer: "ciel"}},
+			issues: []Issue{{Line: 3, Message: `skipped a card without the delimiter " - " between its term and definition`}},
+		},
+		{
+			name:   "card delimiter without new lines",
+			export: "a,1|b,2|c",
+			opts:   ImportOptions{FieldDelimiter: ",", CardDelimiter: "|"},
+			want:   []Problem{{Question: "a", Answer: "1"}, {Question: "b", Answer: "2"}},
+			issues: []Issue{{Line: 1, Message: `skipped a card without the delimiter "," between its term and definition`}},
+		},
+		{
+			name:   "multi-line definitions",
+			export: "sun\tthe star\nat the centre\n\nmoon <img src=\"moon.png\">\tlune\n",
+			opts:   ImportOptions{CardDelimiter: "\n\n"},
+			want:   []Problem{{Question: "sun", Answer: "the star at the centre"}, {Question: "moon", Answer: "lune"}},
+			issues: []Issue{{Line: 4, Message: "dropped image moon.png"}},
+		},
+		{
+			name:   "html",
+			export: "<b>sun</b>\tsoleil &amp; lumière\n",
+			want:   []Problem{{Question: "sun", Answer: "soleil & lumière"}},
+		},
+		{
+			name:   "keep html",
+			export: "<b>sun</b>\tsoleil\n",
+			opts:   ImportOptions{KeepHTML: true, Tags: []string{"french"}},
+			want:   []Problem{{Question: "<b>sun</b>", Answer: "soleil", Tags: []string{"french"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, issues, err := ImportQuizlet(strings.NewReader(tt.export), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problems, tt.want) {
+				t.Errorf("problems =\n%#v\nwant\n%#v", problems, tt.want)
+			}
+			if !reflect.DeepEqual(issues, tt.issues) {
+				t.Errorf("issues =\n%#v\nwant\n%#v", issues, tt.issues)
+			}
+		})
+	}
+}
+
+func TestCardText(t *testing.T) {
+	tests := []struct {
+		s       string
+		plain   bool
+		want    string
+		dropped []string
+	}{
+		{"<p>one</p><p>two</p>", false, "one two", nil},
+		{"a<br/>b", false, "a b", nil},
+		{"x&lt;y &quot;q&quot;", false, `x<y "q"`, nil},
+		{"  spread \n out\t", false, "spread out", nil},
+		{`<IMG SRC="a.png"> [sound:b.mp3]<audio src=c.ogg>`, false, "", []string{"sound b.mp3", "image a.png", "audio c.ogg"}},
+		{"<video>", false, "", []string{"video"}},
+		{"x&lt;y <b>", true, "x&lt;y <b>", nil},
+		{"[sound:b.mp3] word", true, "word", []string{"sound b.mp3"}},
+	}
+	for _, tt := range tests {
+		got, dropped := cardText(tt.s, tt.plain, nil)
+		if got != tt.want || !reflect.DeepEqual(dropped, tt.dropped) {
+			t.Errorf("cardText(%q, %v) = %q, %q, want %q, %q", tt.s, tt.plain, got, dropped, tt.want, tt.dropped)
+		}
+	}
+}
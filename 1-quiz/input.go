@@ -0,0 +1,162 @@
+package quiz
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"reflect"
+	"time"
+)
+
+// inputBuffer is how many lines can be read ahead of the quiz, such as
+// when answers are piped in.
+const inputBuffer = 16
+
+// input reads lines from a reader in a single goroutine for the whole
+// quiz, so that waiting for an answer can be given up on when time runs
+// out without leaving a read behind for every problem.
+type input struct {
+	lines chan inputLine
+	// err is the read error which ended the input, other than io.EOF. It
+	// is set before lines is closed.
+	err error
+	// r is what the lines are read from, through reader, which is shared
+	// with any input which carries on from this one
+	r      io.Reader
+	reader *bufio.Reader
+	// held are the lines which were read once ctx was done, so weren't
+	// passed on. They are kept for an input which carries on from this
+	// one, and are set before done is closed.
+	held []inputLine
+	done chan struct{}
+}
+
+// inputLine is a line read by input.
+type inputLine struct {
+	text string
+	// keys are the times between the keys pressed for the line, when the
+	// reader is a KeystrokeReader
+	keys []time.Duration
+}
+
+// readInput starts reading lines from r. The goroutine stops once r
+// returns an error, or once ctx is done and it next has a line to pass
+// on. A read which is blocked when ctx is done can't be interrupted, so
+// the goroutine lasts until r returns, and that line is held for carryOn
+// rather than passed on.
+func readInput(ctx context.Context, r io.Reader) *input {
+	in := newInput(r, bufio.NewReader(r))
+	go in.pump(ctx, nil)
+	return in
+}
+
+// carryOn starts reading lines from the reader of in once in has stopped,
+// beginning with the lines in read but didn't pass on. It can be called
+// while in is still blocked in a read.
+func (in *input) carryOn(ctx context.Context) *input {
+	next := newInput(in.r, in.reader)
+	go next.pump(ctx, in)
+	return next
+}
+
+// startInput starts reading the answers for a run from r. A run on the
+// same reader as the last one carries on from its input, so that lines
+// typed after the last run stopped go to this one instead of being lost.
+func (q *Quiz) startInput(ctx context.Context, r io.Reader) *input {
+	// Readers which can't be compared are never the same one
+	if last := q.input; last != nil && reflect.TypeOf(r).Comparable() && last.r == r {
+		q.input = last.carryOn(ctx)
+	} else {
+		q.input = readInput(ctx, r)
+	}
+	return q.input
+}
+
+func newInput(r io.Reader, reader *bufio.Reader) *input {
+	return &input{
+		lines:  make(chan inputLine, inputBuffer),
+		r:      r,
+		reader: reader,
+		done:   make(chan struct{}),
+	}
+}
+
+// pump passes on the lines left over from prev, if there is one, and then
+// every line read until the reader fails or ctx is done.
+func (in *input) pump(ctx context.Context, prev *input) {
+	defer close(in.done)
+	defer close(in.lines)
+
+	if prev != nil {
+		// Only one input can read at a time. The lines prev passed on
+		// which were never taken were read before the ones it held.
+		<-prev.done
+		var pending []inputLine
+		for line := range prev.lines {
+			pending = append(pending, line)
+		}
+		pending = append(pending, prev.held...)
+		for i, line := range pending {
+			if !in.send(ctx, line) {
+				in.held = append(in.held, pending[i+1:]...)
+				return
+			}
+		}
+		if prev.err != nil {
+			in.err = prev.err
+			return
+		}
+	}
+
+	keystrokes, _ := in.r.(KeystrokeReader)
+	for {
+		text, err := in.reader.ReadString('\n')
+		if err == nil || text != "" {
+			line := inputLine{text: text}
+			if keystrokes != nil {
+				line.keys = keystrokes.Keystrokes()
+			}
+			if !in.send(ctx, line) {
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				in.err = err
+			}
+			return
+		}
+	}
+}
+
+// send passes on the line, or holds it when ctx is done, reporting whether
+// it was passed on.
+func (in *input) send(ctx context.Context, line inputLine) bool {
+	// A line read after ctx is done is never passed on, even when there is
+	// room for it
+	if ctx.Err() == nil {
+		select {
+		case in.lines <- line:
+			return true
+		case <-ctx.Done():
+		}
+	}
+	in.held = append(in.held, line)
+	return false
+}
+
+// drain throws away any lines which have already been read. They were
+// typed for a problem which has since run out of time, and shouldn't be
+// taken as the answer to the next one.
+func (in *input) drain() {
+	for {
+		select {
+		case _, ok := <-in.lines:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// endlessReader answers forever, like a user holding down enter.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = '\n'
+	}
+	return len(p), nil
+}
+
+// waitForGoroutines waits for the number of goroutines to fall back to
+// want, failing the test if it doesn't.
+func waitForGoroutines(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > want {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines are still running, want %d:\n%s", runtime.NumGoroutine(), want, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReadInputStopsWhenCancelled(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	in := readInput(ctx, endlessReader{})
+
+	// Let the pump fill its buffer and block passing on the next line
+	<-in.lines
+	cancel()
+	waitForGoroutines(t, before)
+
+	for range in.lines {
+	}
+	if in.err != nil {
+		t.Errorf("err = %v, want nil", in.err)
+	}
+}
+
+// lateReader blocks until release is closed and then returns text, like a
+// user who answers after the quiz has stopped waiting.
+type lateReader struct {
+	r       io.Reader
+	release chan struct{}
+}
+
+func newLateReader(text string) *lateReader {
+	return &lateReader{r: strings.NewReader(text), release: make(chan struct{})}
+}
+
+func (l *lateReader) Read(p []byte) (int, error) {
+	<-l.release
+	return l.r.Read(p)
+}
+
+func TestReadInputBlockedRead(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newLateReader("Paris\n")
+	in := readInput(ctx, r)
+
+	// A blocked read can't be interrupted, so the pump lasts until the
+	// reader returns, and then stops without passing its line on
+	cancel()
+	close(r.release)
+	<-in.done
+	if line, ok := <-in.lines; ok {
+		t.Errorf("%q was passed on after the input was cancelled", line.text)
+	}
+
+	// The line is kept for the input which carries on
+	next := in.carryOn(context.Background())
+	if line, ok := <-next.lines; !ok || line.text != "Paris\n" {
+		t.Errorf("carried on with %q, %v, want %q", line.text, ok, "Paris\n")
+	}
+	if line, ok := <-next.lines; ok {
+		t.Errorf("carried on with %q after the held line, want the end of the input", line.text)
+	}
+	waitForGoroutines(t, before)
+}
+
+func TestReadInputCarriesOnInOrder(t *testing.T) {
+	// Lines which were passed on but never taken come before the held one
+	ctx, cancel := context.WithCancel(context.Background())
+	r := newBlockingReader("one\ntwo\n")
+	in := readInput(ctx, r)
+	for len(in.lines) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	close(r.release)
+
+	next := in.carryOn(context.Background())
+	var got []string
+	for line := range next.lines {
+		got = append(got, line.text)
+	}
+	if want := []string{"one\n", "two\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("carried on with %q, want %q", got, want)
+	}
+}
+
+func TestRunCarriesOn(t *testing.T) {
+	// The first run is stopped while waiting for the user to start it, and
+	// what they type afterwards goes to the next run on the same reader
+	in, typed := io.Pipe()
+	clock := newFakeClock()
+	q := New(testProblems, WithClock(clock), WithTimeLimit(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := q.RunContext(ctx, in, io.Discard); !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext returned %v, want %v", err, context.Canceled)
+	}
+
+	go func() {
+		io.WriteString(typed, "\n10\nParis\n10\n")
+		typed.Close()
+	}()
+	result, err := q.Run(in, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Score() != len(testProblems) {
+		t.Errorf("the second run scored %d, want %d: %+v", result.Score(), len(testProblems), result.Answers)
+	}
+}
+
+func TestRunContextLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	r := newBlockingReader("\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := newFakeClock()
+	q := New(testProblems, WithClock(clock), WithTimeLimit(time.Minute))
+
+	done := make(chan error)
+	go func() {
+		_, err := q.RunContext(ctx, r, io.Discard)
+		done <- err
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("RunContext returned %v, want %v", err, context.Canceled)
+	}
+	// The user presses enter or the terminal is closed
+	close(r.release)
+	waitForGoroutines(t, before)
+}
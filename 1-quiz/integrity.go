@@ -0,0 +1,273 @@
+package quiz
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// KeystrokeReader is an input which knows when each key of a line was
+// pressed, such as a terminal which is read a key at a time. The quiz
+// records the timings along with each answer.
+type KeystrokeReader interface {
+	io.Reader
+	// Keystrokes returns the time between each key pressed for the
+	// next line, in the order the lines were read
+	Keystrokes() []time.Duration
+}
+
+// DefaultMaxTypingRate is the fastest answers are taken to be typed, in
+// characters a second, unless the quiz is given another rate with
+// WithMaxTypingRate. It is well above what even fast typists keep up.
+const DefaultMaxTypingRate = 25
+
+// pasteMinLength is the length of the shortest answer which can be taken
+// to be pasted, since a few keys can be pressed almost at once
+const pasteMinLength = 5
+
+// WithMaxTypingRate sets the fastest answers can be typed, in characters a
+// second. Answers which arrive faster than that are marked as pasted. A
+// rate of zero turns the check off.
+func WithMaxTypingRate(rate float64) Option {
+	return func(q *Quiz) {
+		q.maxTypingRate = rate
+	}
+}
+
+// pasted reports whether an answer arrived faster than it could have been
+// typed. With keystroke timings only the time spent typing counts,
+// otherwise the whole time since the problem was shown does.
+func (q *Quiz) pasted(given string, keys []time.Duration, latency time.Duration) bool {
+	n := utf8.RuneCountInString(given)
+	if q.maxTypingRate <= 0 || n < pasteMinLength {
+		return false
+	}
+	if keys != nil {
+		// Every key counts, including any which were deleted again
+		n = len(keys) + 1
+		latency = 0
+		for _, k := range keys {
+			latency += k
+		}
+	}
+	return float64(n)/latency.Seconds() > q.maxTypingRate
+}
+
+// Sign returns the HMAC-SHA256 of data under key, hex encoded. It is used
+// for reports, which are signed on the machine the quiz runs on.
+func Sign(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is the signature of data
+// under key made by Sign.
+func VerifySignature(key, data []byte, signature string) bool {
+	sum, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// Manifest lists the banks a quiz may be run with by the SHA-256 hash of
+// each, and is signed with an Ed25519 key so that neither it nor the banks
+// can be changed without the private key. The quiz only needs the public
+// key to check it, so the people taking the quiz can't sign manifests of
+// their own.
+type Manifest struct {
+	// Files maps the paths of the banks to their hex encoded hashes
+	Files     map[string]string `json:"files"`
+	Signature string            `json:"signature"`
+}
+
+// NewManifest hashes the bank files and signs the list with key.
+func NewManifest(key ed25519.PrivateKey, files []string) (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]string, len(files))}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		m.Files[filepath.ToSlash(filepath.Clean(file))] = hashBank(data)
+	}
+	data, err := m.signed()
+	if err != nil {
+		return nil, err
+	}
+	m.Signature = hex.EncodeToString(ed25519.Sign(key, data))
+	return m, nil
+}
+
+// LoadManifest reads a manifest saved with Save.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest to path.
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Verify checks that the manifest was signed with the private half of
+// key.
+func (m *Manifest) Verify(key ed25519.PublicKey) error {
+	data, err := m.signed()
+	if err != nil {
+		return err
+	}
+	signature, err := hex.DecodeString(m.Signature)
+	if err != nil || !ed25519.Verify(key, data, signature) {
+		return errors.New("the manifest's signature doesn't match, it was changed or signed with another key")
+	}
+	return nil
+}
+
+// Check verifies that data is the bank listed in the manifest for path.
+func (m *Manifest) Check(path string, data []byte) error {
+	want, ok := m.Files[filepath.ToSlash(filepath.Clean(path))]
+	if !ok {
+		return fmt.Errorf("%s is not in the manifest, which lists %s", path, m.list())
+	}
+	if hashBank(data) != want {
+		return fmt.Errorf("%s has been changed since the manifest was made", path)
+	}
+	return nil
+}
+
+// signed returns the data the signature of the manifest covers. Maps are
+// encoded with their keys sorted, so the same files always sign the same.
+func (m *Manifest) signed() ([]byte, error) {
+	return json.Marshal(m.Files)
+}
+
+func (m *Manifest) list() string {
+	files := make([]string, 0, len(m.Files))
+	for f := range m.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return strings.Join(files, ", ")
+}
+
+func hashBank(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadKey reads the key reports are signed with from a file. Surrounding
+// whitespace is ignored so that the key can be kept as a line of text.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < 16 {
+		return nil, fmt.Errorf("%s: the key is too short, use at least 16 characters", path)
+	}
+	return key, nil
+}
+
+// publicKeyExt is added to the path of a manifest key for the file its
+// public half is kept in.
+const publicKeyExt = ".pub"
+
+// GenerateManifestKey makes a new key for signing manifests, writing the
+// private key to path and the public key to path+".pub". The private key
+// is only readable by its owner.
+func GenerateManifestKey(path string) error {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(private)+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(path+publicKeyExt, []byte(hex.EncodeToString(public)+"\n"), 0o644)
+}
+
+// LoadManifestKey reads a private key written by GenerateManifestKey.
+func LoadManifestKey(path string) (ed25519.PrivateKey, error) {
+	key, err := loadHexKey(path, "private", ed25519.PrivateKeySize)
+	return ed25519.PrivateKey(key), err
+}
+
+// LoadManifestPublicKey reads a public key written by
+// GenerateManifestKey.
+func LoadManifestPublicKey(path string) (ed25519.PublicKey, error) {
+	key, err := loadHexKey(path, "public", ed25519.PublicKeySize)
+	return ed25519.PublicKey(key), err
+}
+
+func loadHexKey(path, kind string, size int) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != size {
+		return nil, fmt.Errorf("%s: not a %s key made by quiz keygen", path, kind)
+	}
+	return key, nil
+}
+
+// signatureExt is added to the path of a file for the file its signature
+// is kept in.
+const signatureExt = ".sig"
+
+// SignFile signs the file at path with key, writing the signature next to
+// it in path+".sig".
+func SignFile(key []byte, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+signatureExt, []byte(Sign(key, data)+"\n"), 0o644)
+}
+
+// VerifyFile checks the file at path against the signature written by
+// SignFile, returning an error when it has been changed since it was
+// signed.
+func VerifyFile(key []byte, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	signature, err := os.ReadFile(path + signatureExt)
+	if err != nil {
+		return err
+	}
+	if !VerifySignature(key, data, string(bytes.TrimSpace(signature))) {
+		return fmt.Errorf("%s doesn't match its signature, it was changed or signed with another key", path)
+	}
+	return nil
+}
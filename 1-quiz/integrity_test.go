@@ -0,0 +1,50 @@
+package quiz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	bank := filepath.Join(dir, "problems.csv")
+	if err := os.WriteFile(bank, []byte("5+5,10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "exam.key")
+	if err := GenerateManifestKey(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	private, err := LoadManifestKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := LoadManifestPublicKey(keyFile + publicKeyExt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifestPublicKey(keyFile); err == nil {
+		t.Error("the private key was read as a public key")
+	}
+
+	m, err := NewManifest(private, []string{bank})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Verify(public); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := m.Check(bank, []byte("5+5,10\n")); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if err := m.Check(bank, []byte("5+5,11\n")); err == nil {
+		t.Error("Check() accepted a changed bank")
+	}
+
+	// Listing another bank breaks the signature
+	m.Files["other.csv"] = hashBank([]byte("1+1,2\n"))
+	if err := m.Verify(public); err == nil {
+		t.Error("Verify() accepted a changed manifest")
+	}
+}
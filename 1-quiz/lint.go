@@ -0,0 +1,380 @@
+package quiz
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Issue is something wrong with a problem bank found by Lint.
+type Issue struct {
+	// Line is the line of the bank the issue is on, or zero when it isn't
+	// known
+	Line int
+	// Problem is the problem the issue is with counting from 1, or zero
+	// when the issue isn't with a single problem
+	Problem int
+	Message string
+}
+
+// Format returns the issue as "path:line: message", the way compilers
+// report errors, so that editors and CI tools can find it.
+func (i Issue) Format(path string) string {
+	switch {
+	case i.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", path, i.Line, i.Message)
+	case i.Problem > 0:
+		return fmt.Sprintf("%s: problem %d: %s", path, i.Problem, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", path, i.Message)
+}
+
+// LintFile checks the problem bank at path with Lint, using the format
+// given by its extension.
+func LintFile(path string, m Matcher) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Lint(data, filepath.Ext(path), m)
+}
+
+// Lint checks a problem bank in the format given by the file extension ext
+// and returns every issue found in the order of the bank, rather than
+// stopping at the first like the loaders do. On top of what the loaders
+// check it finds duplicate questions, answers which their matcher can
+// never accept and text which isn't valid UTF-8. m is the matcher used for
+// problems without their own, or nil for DefaultMatcher.
+//
+// Banks in formats without a linter of their own are read with the loader
+// registered for ext, so only the first issue which stops them loading is
+// found.
+func Lint(data []byte, ext string, m Matcher) ([]Issue, error) {
+	if m == nil {
+		m = DefaultMatcher
+	}
+	var l linter
+	data = l.checkEncoding(data)
+	if data == nil {
+		return l.issues, nil
+	}
+
+	switch strings.ToLower(ext) {
+	case ".csv":
+		l.lintCSV(data)
+	case ".json":
+		l.lintJSON(data)
+	case ".yaml", ".yml":
+		l.lintYAML(data)
+	case ".toml":
+		l.lintTOML(data)
+	default:
+		loader, ok := loaders[strings.ToLower(ext)]
+		if !ok {
+			return nil, fmt.Errorf("no loader for %q files", ext)
+		}
+		problems, err := loader(bytes.NewReader(data))
+		if err != nil {
+			l.add(0, 0, "%v", err)
+		}
+		for i, p := range problems {
+			l.problems = append(l.problems, lintedProblem{Problem: p, n: i + 1})
+		}
+	}
+
+	l.checkProblems(m)
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	return l.issues, nil
+}
+
+// linter collects the issues found in a bank.
+type linter struct {
+	issues []Issue
+	// problems are the problems which could be read from the bank
+	problems []lintedProblem
+}
+
+// lintedProblem is a problem read from a bank along with where it is.
+type lintedProblem struct {
+	Problem
+	line int
+	n    int
+}
+
+func (l *linter) add(line, n int, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{Line: line, Problem: n, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkEncoding reports text which isn't UTF-8 and returns the data with
+// any byte order mark removed, or nil when the bank can't be read at all.
+func (l *linter) checkEncoding(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}), bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		l.add(1, 0, "the bank is saved as UTF-16, save it as UTF-8 instead")
+		return nil
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		l.add(1, 0, "the bank starts with a byte order mark, save it as UTF-8 without one")
+		data = data[3:]
+	}
+
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		if !utf8.Valid(line) {
+			l.add(n+1, 0, "invalid UTF-8, the bank may be saved in another encoding such as Latin-1")
+			continue
+		}
+		for _, r := range string(line) {
+			if unicode.IsControl(r) && r != '\t' && r != '\r' {
+				l.add(n+1, 0, "contains the control character %U", r)
+				break
+			}
+		}
+	}
+	return data
+}
+
+func (l *linter) lintCSV(data []byte) {
+	records, rows, err := readCSVRecords(bytes.NewReader(data), func(row int, err error) error {
+		l.add(row, 0, "%v", err)
+		return nil
+	})
+	if err != nil {
+		l.add(0, 0, "%v", err)
+	}
+	l.build(records, rows)
+}
+
+func (l *linter) lintJSON(data []byte) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	var bank bankFile
+	if err := d.Decode(&bank); err != nil {
+		l.add(jsonErrorLine(data, err), 0, "%v", err)
+		return
+	}
+	l.build(bank.Problems, jsonProblemLines(data))
+}
+
+func (l *linter) lintYAML(data []byte) {
+	var bank bankFile
+	if err := yaml.UnmarshalStrict(data, &bank); err != nil {
+		// Type errors list every field which couldn't be decoded, each
+		// with its own line
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for _, msg := range typeErr.Errors {
+				line, msg := yamlErrorLine(msg)
+				l.add(line, 0, "%s", msg)
+			}
+		} else {
+			line, msg := yamlErrorLine(strings.TrimPrefix(err.Error(), "yaml: "))
+			l.add(line, 0, "%s", msg)
+		}
+		return
+	}
+	l.build(bank.Problems, yamlProblemLines(data))
+}
+
+func (l *linter) lintTOML(data []byte) {
+	var bank bankFile
+	md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(&bank)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			// Some errors only have a message in the text of the error,
+			// after where they are
+			msg := parseErr.Message
+			if msg == "" {
+				msg = tomlErrorPrefix.ReplaceAllString(parseErr.Error(), "")
+			}
+			l.add(parseErr.Position.Line, 0, "%s", msg)
+		} else {
+			l.add(0, 0, "%v", err)
+		}
+		return
+	}
+	for _, key := range md.Undecoded() {
+		l.add(tomlKeyLine(data, key[len(key)-1]), 0, "unknown field %q", key.String())
+	}
+	l.build(bank.Problems, tomlProblemLines(data))
+}
+
+// build validates every record, keeping the ones which are valid for the
+// checks across problems. lines are the lines each record starts on, or
+// nil when they aren't known.
+func (l *linter) build(records []bankProblem, lines []int) {
+	if len(lines) != len(records) {
+		lines = nil
+	}
+	for i, rec := range records {
+		line := 0
+		if lines != nil {
+			line = lines[i]
+		}
+		p, err := rec.problem()
+		if err != nil {
+			l.add(line, i+1, "%v", err)
+			continue
+		}
+		l.problems = append(l.problems, lintedProblem{Problem: p, line: line, n: i + 1})
+	}
+}
+
+// checkProblems looks for issues across the problems of the bank and with
+// the answers of each one.
+func (l *linter) checkProblems(m Matcher) {
+	ids := make(map[string]lintedProblem)
+	questions := make(map[string]lintedProblem)
+	for _, p := range l.problems {
+		if p.ID != "" {
+			if first, ok := ids[p.ID]; ok {
+				l.add(p.line, p.n, "duplicate id %q, first used at %s", p.ID, first.where())
+			} else {
+				ids[p.ID] = p
+			}
+		}
+
+		// Questions are compared ignoring case and spacing
+		key := strings.ToLower(strings.Join(strings.Fields(p.Question), " "))
+		if first, ok := questions[key]; ok {
+			l.add(p.line, p.n, "duplicate question %q, first asked at %s", p.Question, first.where())
+		} else {
+			questions[key] = p
+		}
+
+		// The answers to multiple choice problems are option letters which
+		// have already been checked, and sealed answers can't be
+		if p.IsMultipleChoice() || p.IsSealed() {
+			continue
+		}
+		matcher := m
+		if p.Matcher != nil {
+			matcher = p.Matcher
+		}
+		for _, a := range p.AcceptedAnswers() {
+			if msg := checkAnswer(matcher, a); msg != "" {
+				l.add(p.line, p.n, "%s", msg)
+			}
+		}
+	}
+}
+
+func (p lintedProblem) where() string {
+	if p.line > 0 {
+		return fmt.Sprintf("line %d", p.line)
+	}
+	return fmt.Sprintf("problem %d", p.n)
+}
+
+// checkAnswer returns why the matcher can never accept the answer, or ""
+// when it can.
+func checkAnswer(m Matcher, answer string) string {
+	switch m := m.(type) {
+	case Numeric:
+		n, err := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+		if err != nil {
+			return fmt.Sprintf("answer %q is not a number, but the problem is matched as numeric", answer)
+		}
+		if math.IsNaN(n) {
+			return fmt.Sprintf("answer %q can never be matched", answer)
+		}
+	case Regex:
+		if _, err := regexp.Compile(strings.TrimSpace(answer)); err != nil {
+			return fmt.Sprintf("answer %q is not a valid regular expression: %v", answer, err)
+		}
+	case AnyOf:
+		for _, part := range strings.Split(answer, m.Delimiter) {
+			if strings.TrimSpace(part) == "" {
+				return fmt.Sprintf("answer %q has an empty alternative", answer)
+			}
+			if msg := checkAnswer(m.Matcher, part); msg != "" {
+				return msg
+			}
+		}
+	default:
+		if !m.Match(strings.TrimSpace(answer), answer) {
+			return fmt.Sprintf("answer %q is not accepted by its own matcher", answer)
+		}
+	}
+	return ""
+}
+
+// jsonProblemLines returns the line each problem of a JSON bank starts on,
+// or nil when they can't be found.
+func jsonProblemLines(data []byte) []int {
+	spans, ok := jsonProblemSpans(data)
+	if !ok {
+		return nil
+	}
+	lines := make([]int, len(spans.starts))
+	for i, start := range spans.starts {
+		lines[i] = lineAt(data, start)
+	}
+	return lines
+}
+
+// yamlProblemLines returns the line each problem of a YAML bank starts on,
+// which are the items of the problems list written in block style.
+func yamlProblemLines(data []byte) []int {
+	return yamlProblemList(data).items
+}
+
+// yamlLinePrefix is how the YAML decoder starts its errors with the line
+// they are on.
+var yamlLinePrefix = regexp.MustCompile(`^line (\d+): `)
+
+// yamlErrorLine takes the line off the front of an error from the YAML
+// decoder, returning zero when it doesn't say.
+func yamlErrorLine(msg string) (int, string) {
+	m := yamlLinePrefix.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, msg
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, msg[len(m[0]):]
+}
+
+// tomlErrorPrefix is where a TOML error is, ahead of its message.
+var tomlErrorPrefix = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+
+// tomlKeyLine returns the first line of a TOML bank which sets key, or
+// zero when there isn't one.
+func tomlKeyLine(data []byte, key string) int {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		name, _, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.Trim(strings.TrimSpace(name), `"'`) == key {
+			return n
+		}
+	}
+	return 0
+}
+
+// tomlProblemLines returns the line each [[problems]] table of a TOML bank
+// starts on.
+func tomlProblemLines(data []byte) []int {
+	var lines []int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.ReplaceAll(text, " ", "") == "[[problems]]" {
+			lines = append(lines, n)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,148 @@
+package quiz
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		bank string
+		want []Issue
+	}{
+		{
+			name: "csv",
+			ext:  ".csv",
+			bank: "5+5,10\nq2\n5+5 ,11\npi,abc,,numeric\nbad,(,,regex\nyn,yes||y,,anyof\nempty,\nbell\x07,x\n",
+			want: []Issue{
+				{Line: 2, Message: "expected 'question,answer'"},
+				{Line: 3, Problem: 2, Message: `duplicate question "5+5", first asked at line 1`},
+				{Line: 4, Problem: 3, Message: `answer "abc" is not a number, but the problem is matched as numeric`},
+				{Line: 5, Problem: 4, Message: "answer \"(\" is not a valid regular expression: error parsing regexp: missing closing ): `(`"},
+				{Line: 6, Problem: 5, Message: `answer "yes||y" has an empty alternative`},
+				{Line: 7, Problem: 6, Message: "missing answer"},
+				{Line: 8, Message: "contains the control character U+0007"},
+			},
+		},
+		{
+			name: "csv with a header",
+			ext:  ".csv",
+			bank: "question,answer,tags\n5+5,10,a\nq,,b\n",
+			want: []Issue{{Line: 3, Problem: 2, Message: "missing answer"}},
+		},
+		{
+			name: "csv encoding",
+			ext:  ".csv",
+			bank: "\xef\xbb\xbf5+5,10\nq,caf\xe9\n",
+			want: []Issue{
+				{Line: 1, Message: "the bank starts with a byte order mark, save it as UTF-8 without one"},
+				{Line: 2, Message: "invalid UTF-8, the bank may be saved in another encoding such as Latin-1"},
+			},
+		},
+		{
+			name: "clean csv",
+			ext:  ".csv",
+			bank: "5+5,10\n7+3,10\n",
+			want: nil,
+		},
+		{
+			name: "json",
+			ext:  ".json",
+			bank: "{\n  \"problems\": [\n    {\"question\": \"5+5\", \"answer\": \"10\"},\n    {\"question\": \"5+5\", \"answer\": \"11\"},\n    {\"question\": \"pi\", \"answer\": \"x\", \"match\": \"numeric\"},\n    {\"question\": \"q\"}\n  ]\n}\n",
+			want: []Issue{
+				{Line: 4, Problem: 2, Message: `duplicate question "5+5", first asked at line 3`},
+				{Line: 5, Problem: 3, Message: `answer "x" is not a number, but the problem is matched as numeric`},
+				{Line: 6, Problem: 4, Message: "missing answer"},
+			},
+		},
+		{
+			name: "json syntax",
+			ext:  ".json",
+			bank: "{\n  \"problems\": [\n    {\"question\": \"5+5\", \"answer\": \"10\"},\n  ]\n}\n",
+			want: []Issue{{Line: 4, Message: "invalid character ']' looking for beginning of value"}},
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			bank: "problems:\n  - id: a\n    question: 5+5\n    answer: \"10\"\n  - id: a\n    question: q2\n    answer: x\n  - question: r\n    answer: \"(\"\n    match: regex\n  - question: opt\n    options: [x, y]\n    answer: D\n",
+			want: []Issue{
+				{Line: 5, Problem: 2, Message: `duplicate id "a", first used at line 2`},
+				{Line: 8, Problem: 3, Message: "answer \"(\" is not a valid regular expression: error parsing regexp: missing closing ): `(`"},
+				{Line: 11, Problem: 4, Message: "invalid answer: 'D' is not one of the options A-B"},
+			},
+		},
+		{
+			name: "yaml syntax",
+			ext:  ".yaml",
+			bank: "problems:\n  - question: 5+5\n    answer: [\n",
+			want: []Issue{{Line: 3, Message: "did not find expected node content"}},
+		},
+		{
+			name: "yaml unknown field",
+			ext:  ".yaml",
+			bank: "problems:\n  - question: 5+5\n    answer: \"10\"\n    colour: red\n",
+			want: []Issue{{Line: 4, Message: "field colour not found in type quiz.bankProblem"}},
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			bank: "[[problems]]\nquestion = \"5+5\"\nanswer = \"10\"\n\n[[problems]]\nquestion = \"5+5\"\nanswer = \"x\"\nmatch = \"bogus\"\n\n[[problems]]\nquestion = \"q\"\nanswer = \"a\"\ncolour = \"red\"\n",
+			want: []Issue{
+				{Line: 5, Problem: 2, Message: `unknown matcher "bogus"`},
+				{Line: 13, Message: `unknown field "problems.colour"`},
+			},
+		},
+		{
+			name: "toml syntax",
+			ext:  ".toml",
+			bank: "[[problems]]\nquestion = \"5+5\nanswer = \"10\"\n",
+			want: []Issue{{Line: 2, Message: "strings cannot contain newlines"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lint([]byte(tt.bank), tt.ext, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintMatcher(t *testing.T) {
+	// The matcher given to Lint applies to problems without their own
+	bank := []byte("pi,3.14\nname,Ada\n")
+	got, err := Lint(bank, ".csv", Numeric{Tolerance: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Issue{{Line: 2, Problem: 2, Message: `answer "Ada" is not a number, but the problem is matched as numeric`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %#v, want %#v", got, want)
+	}
+
+	if _, err := Lint(bank, ".txt", nil); err == nil {
+		t.Error("Lint() of an unknown format didn't fail")
+	}
+}
+
+func TestIssueFormat(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{Line: 3, Problem: 2, Message: "missing answer"}, "bank.csv:3: missing answer"},
+		{Issue{Problem: 2, Message: "missing answer"}, "bank.csv: problem 2: missing answer"},
+		{Issue{Message: "empty"}, "bank.csv: empty"},
+	}
+	for _, tt := range tests {
+		if got := tt.issue.Format("bank.csv"); got != tt.want {
+			t.Errorf("Format() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher decides whether the answer given by the user is correct. The
+// given answer has already had surrounding whitespace removed.
+type Matcher interface {
+	Match(given, expected string) bool
+}
+
+// MatcherFunc lets ordinary functions be used as a Matcher.
+type MatcherFunc func(given, expected string) bool
+
+func (fn MatcherFunc) Match(given, expected string) bool {
+	return fn(given, expected)
+}
+
+// DefaultMatcher is used for problems without a Matcher of their own when
+// the quiz is not given another one with WithMatcher.
+var DefaultMatcher Matcher = CaseInsensitive{}
+
+// Exact matches answers which are identical to the expected answer.
+type Exact struct{}
+
+func (Exact) Match(given, expected string) bool {
+	return given == strings.TrimSpace(expected)
+}
+
+// CaseInsensitive matches answers which are equal to the expected answer
+// when case is ignored.
+type CaseInsensitive struct{}
+
+func (CaseInsensitive) Match(given, expected string) bool {
+	return strings.EqualFold(given, strings.TrimSpace(expected))
+}
+
+// Numeric matches answers which are numbers within Tolerance of the
+// expected answer, so "0.50" matches "0.5".
+type Numeric struct {
+	Tolerance float64
+}
+
+func (m Numeric) Match(given, expected string) bool {
+	g, err := strconv.ParseFloat(given, 64)
+	if err != nil {
+		return false
+	}
+	e, err := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+	if err != nil {
+		return false
+	}
+	return math.Abs(g-e) <= m.Tolerance
+}
+
+// Regex treats the expected answer as a regular expression which has to
+// match the whole of the given answer.
+type Regex struct{}
+
+func (Regex) Match(given, expected string) bool {
+	re, err := regexp.Compile("^(?:" + strings.TrimSpace(expected) + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(given)
+}
+
+// AnyOf splits the expected answer on Delimiter and accepts the given
+// answer if Matcher accepts it for any of the parts.
+type AnyOf struct {
+	Delimiter string
+	Matcher   Matcher
+}
+
+func (m AnyOf) Match(given, expected string) bool {
+	for _, option := range strings.Split(expected, m.Delimiter) {
+		if m.Matcher.Match(given, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fuzzy matches answers which are at most MaxDistance edits away from the
+// expected answer, ignoring case. This forgives small typos in text
+// answers.
+type Fuzzy struct {
+	MaxDistance int
+}
+
+func (m Fuzzy) Match(given, expected string) bool {
+	given = strings.ToLower(given)
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	return levenshtein(given, expected) <= m.MaxDistance
+}
+
+// levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// Only the previous row of the distance table is needed
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// ParseMatcher returns the matcher described by spec. The spec is the name
+// of a matcher optionally followed by a colon and an argument:
+//
+//	exact
+//	nocase
+//	numeric[:tolerance]    e.g. numeric:0.01
+//	regex
+//	anyof[:delimiter]      e.g. anyof:;  (defaults to "|")
+//	fuzzy[:max distance]   e.g. fuzzy:2  (defaults to 2)
+//
+// The empty spec returns nil so that the quiz default is used.
+func ParseMatcher(spec string) (Matcher, error) {
+	spec = strings.TrimSpace(spec)
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch strings.ToLower(name) {
+	case "":
+		return nil, nil
+	case "exact":
+		return Exact{}, nil
+	case "nocase":
+		return CaseInsensitive{}, nil
+	case "numeric":
+		m := Numeric{Tolerance: 1e-9}
+		if hasArg {
+			tolerance, err := strconv.ParseFloat(arg, 64)
+			if err != nil || tolerance < 0 {
+				return nil, fmt.Errorf("invalid numeric tolerance %q", arg)
+			}
+			m.Tolerance = tolerance
+		}
+		return m, nil
+	case "regex":
+		return Regex{}, nil
+	case "anyof":
+		m := AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}
+		if hasArg && arg != "" {
+			m.Delimiter = arg
+		}
+		return m, nil
+	case "fuzzy":
+		m := Fuzzy{MaxDistance: 2}
+		if hasArg {
+			distance, err := strconv.Atoi(arg)
+			if err != nil || distance < 0 {
+				return nil, fmt.Errorf("invalid fuzzy distance %q", arg)
+			}
+			m.MaxDistance = distance
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown matcher %q", spec)
+}
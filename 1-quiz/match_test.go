@@ -0,0 +1,118 @@
+package quiz
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Matcher
+		// err is whether the spec should be rejected
+		err bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"exact", Exact{}, false},
+		{"NoCase", CaseInsensitive{}, false},
+		{"numeric", Numeric{Tolerance: 1e-9}, false},
+		{"numeric:0.01", Numeric{Tolerance: 0.01}, false},
+		{"numeric:-1", nil, true},
+		{"numeric:abc", nil, true},
+		{"regex", Regex{}, false},
+		{"anyof", AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}, false},
+		{"anyof:;", AnyOf{Delimiter: ";", Matcher: CaseInsensitive{}}, false},
+		{"anyof:", AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}, false},
+		{"fuzzy", Fuzzy{MaxDistance: 2}, false},
+		{"fuzzy:0", Fuzzy{MaxDistance: 0}, false},
+		{"fuzzy:x", nil, true},
+		{"fuzzy:-1", nil, true},
+		{"soundex", nil, true},
+		{"exactly", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMatcher(tt.spec)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseMatcher(%q) error = %v, want error %v", tt.spec, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMatcher(%q) = %#v, want %#v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Matcher
+		given    string
+		expected string
+		want     bool
+	}{
+		{"exact", Exact{}, "Paris", "Paris ", true},
+		{"exact case", Exact{}, "paris", "Paris", false},
+		{"nocase", CaseInsensitive{}, "pARIS", "Paris", true},
+		{"nocase wrong", CaseInsensitive{}, "Lyon", "Paris", false},
+
+		{"numeric trailing zero", Numeric{Tolerance: 1e-9}, "2.50", "2.5", true},
+		{"numeric trailing zero expected", Numeric{Tolerance: 1e-9}, "2.5", "2.50", true},
+		{"numeric integer", Numeric{Tolerance: 1e-9}, "10", "10.0", true},
+		{"numeric within tolerance", Numeric{Tolerance: 0.01}, "3.145", "3.14", true},
+		{"numeric at tolerance", Numeric{Tolerance: 0.5}, "3.5", "3", true},
+		{"numeric outside tolerance", Numeric{Tolerance: 0.01}, "3.16", "3.14", false},
+		{"numeric not a number", Numeric{Tolerance: 0.01}, "pi", "3.14", false},
+		{"numeric expected not a number", Numeric{Tolerance: 0.01}, "3.14", "pi", false},
+
+		{"regex", Regex{}, "color", "colou?r", true},
+		{"regex alternative", Regex{}, "colour", "colou?r", true},
+		{"regex is anchored at the end", Regex{}, "colors", "colou?r", false},
+		{"regex is anchored at the start", Regex{}, "a color", "colou?r", false},
+		{"regex alternation is anchored", Regex{}, "dogs", "cat|dog", false},
+		{"regex invalid", Regex{}, "(", "(", false},
+
+		{"anyof", AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}, "Y", "yes|y", true},
+		{"anyof none", AnyOf{Delimiter: "|", Matcher: CaseInsensitive{}}, "no", "yes|y", false},
+		{
+			"nested anyof", AnyOf{Delimiter: ";", Matcher: AnyOf{Delimiter: "|", Matcher: Numeric{Tolerance: 0.1}}},
+			"2.05", "1|1.0;2|two", true,
+		},
+		{
+			"nested anyof none", AnyOf{Delimiter: ";", Matcher: AnyOf{Delimiter: "|", Matcher: Exact{}}},
+			"2;two", "1|one;2|two", false,
+		},
+
+		{"fuzzy exact", Fuzzy{MaxDistance: 2}, "Mississippi", "mississippi", true},
+		{"fuzzy under the threshold", Fuzzy{MaxDistance: 2}, "Missisippi", "Mississippi", true},
+		{"fuzzy at the threshold", Fuzzy{MaxDistance: 2}, "Missisipi", "Mississippi", true},
+		{"fuzzy over the threshold", Fuzzy{MaxDistance: 2}, "Misisipi", "Mississippi", false},
+		{"fuzzy zero", Fuzzy{MaxDistance: 0}, "Pari", "Paris", false},
+		{"fuzzy runes", Fuzzy{MaxDistance: 1}, "Zurich", "Zürich", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.Match(tt.given, tt.expected); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.given, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
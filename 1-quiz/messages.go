@@ -0,0 +1,355 @@
+package quiz
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Messages is the text shown to the user in one language. Fields which
+// take values note them as format verbs.
+type Messages struct {
+	Start string
+	// Problem takes the problem number and the question
+	Problem    string
+	ChooseOne  string
+	ChooseMany string
+	// Hint takes the hint number, how many hints there are and the hint
+	Hint          string
+	NoHints       string
+	TimeUp        string
+	ProblemTimeUp string
+	Correct       string
+	// PartlyRight and Wrong take the expected answer
+	PartlyRight string
+	Wrong       string
+	// Paused is shown when the quiz can't be saved, PausedUnsaveable when
+	// it picks its problems as it goes, PausedFailed with the error when
+	// saving failed, and PausedSaved when it was saved
+	Paused           string
+	PausedUnsaveable string
+	PausedFailed     string
+	PausedSaved      string
+	Missed           string
+	// YouAnswered takes the given and the expected answer
+	YouAnswered string
+	TimedOut    string
+	Nothing     string
+	// Sealed is shown in place of the expected answer of sealed problems
+	Sealed string
+	// Score takes the points scored and the points available
+	Score       string
+	Interrupted string
+	// CarryOnLater takes the checkpoint file
+	CarryOnLater string
+	// CarryingOn takes the problem number and how many problems there are
+	CarryingOn string
+	// Skill takes the skill rating and the difficulty level
+	Skill string
+	// NothingToReview takes the time the next problem is due
+	NothingToReview string
+	// Help takes the hint and the pause commands
+	Help string
+
+	// The rest are only used by the full screen terminal UI. Incorrect
+	// says an answer was wrong without giving the answer away.
+	Incorrect string
+	Title     string
+	// Progress takes the problem number and how many problems there are
+	Progress string
+	// RunningScore takes the points so far and the points available so
+	// far
+	RunningScore string
+	// QuizTimer and ProblemTimer label the countdowns
+	QuizTimer    string
+	ProblemTimer string
+	// TimeLeft takes the time left as minutes and seconds
+	TimeLeft    string
+	TimerPaused string
+}
+
+// English is the default language of the quiz.
+var English = Messages{
+	Start:            "Press enter to start the quiz...",
+	Problem:          "Problem #%d: %s",
+	ChooseOne:        "Choose one: ",
+	ChooseMany:       "Choose all that apply (e.g. A,C): ",
+	Hint:             "Hint %d/%d: %s",
+	NoHints:          "No more hints for this problem.",
+	TimeUp:           "Time's up!",
+	ProblemTimeUp:    "Out of time for this problem!",
+	Correct:          "Correct!",
+	PartlyRight:      "Partly right, the answer is %s.",
+	Wrong:            "Wrong, the answer is %s.",
+	Paused:           "Paused. Press enter to carry on...",
+	PausedUnsaveable: "Paused, but this quiz picks its problems as it goes so it can't be saved.\nPress enter to carry on...",
+	PausedFailed:     "Paused, but couldn't save a checkpoint: %v\nPress enter to carry on...",
+	PausedSaved:      "Paused and saved. Press enter to carry on, or stop the quiz and resume it later...",
+	Missed:           "Missed:",
+	YouAnswered:      "you answered %s, the answer is %s",
+	TimedOut:         "(timed out)",
+	Nothing:          "(nothing)",
+	Sealed:           "(sealed)",
+	Score:            "You scored %s out of %s.",
+	Interrupted:      "Interrupted!",
+	CarryOnLater:     "Carry on later with: quiz -resume %s",
+	CarryingOn:       "Carrying on from problem #%d of %d.",
+	Skill:            "Estimated skill: %.0f, about difficulty %.1f.",
+	NothingToReview:  "Nothing to review until %s.",
+	Help:             "%s for a hint, %s to pause, Ctrl+C to stop",
+	Incorrect:        "Wrong.",
+	Title:            "Quiz",
+	Progress:         "Problem %d/%d",
+	RunningScore:     "Score %s/%s",
+	QuizTimer:        "Quiz",
+	ProblemTimer:     "Problem",
+	TimeLeft:         "%s left",
+	TimerPaused:      "paused",
+}
+
+// Spanish is the quiz in Spanish.
+var Spanish = Messages{
+	Start:            "Pulsa enter para empezar el cuestionario...",
+	Problem:          "Pregunta n.º %d: %s",
+	ChooseOne:        "Elige una: ",
+	ChooseMany:       "Elige todas las correctas (p. ej. A,C): ",
+	Hint:             "Pista %d/%d: %s",
+	NoHints:          "No quedan más pistas para esta pregunta.",
+	TimeUp:           "¡Se acabó el tiempo!",
+	ProblemTimeUp:    "¡Se acabó el tiempo para esta pregunta!",
+	Correct:          "¡Correcto!",
+	PartlyRight:      "En parte correcto, la respuesta es %s.",
+	Wrong:            "Incorrecto, la respuesta es %s.",
+	Paused:           "En pausa. Pulsa enter para continuar...",
+	PausedUnsaveable: "En pausa, pero este cuestionario elige las preguntas sobre la marcha y no se puede guardar.\nPulsa enter para continuar...",
+	PausedFailed:     "En pausa, pero no se pudo guardar el progreso: %v\nPulsa enter para continuar...",
+	PausedSaved:      "En pausa y guardado. Pulsa enter para continuar, o sal y retómalo más tarde...",
+	Missed:           "Falladas:",
+	YouAnswered:      "respondiste %s, la respuesta es %s",
+	TimedOut:         "(sin tiempo)",
+	Nothing:          "(nada)",
+	Sealed:           "(sellada)",
+	Score:            "Has obtenido %s de %s puntos.",
+	Interrupted:      "¡Interrumpido!",
+	CarryOnLater:     "Continúa más tarde con: quiz -resume %s",
+	CarryingOn:       "Continuando desde la pregunta n.º %d de %d.",
+	Skill:            "Nivel estimado: %.0f, alrededor de la dificultad %.1f.",
+	NothingToReview:  "Nada que repasar hasta el %s.",
+	Help:             "%s para una pista, %s para pausar, Ctrl+C para salir",
+	Incorrect:        "Incorrecto.",
+	Title:            "Cuestionario",
+	Progress:         "Pregunta %d/%d",
+	RunningScore:     "Puntos %s/%s",
+	QuizTimer:        "Total",
+	ProblemTimer:     "Pregunta",
+	TimeLeft:         "quedan %s",
+	TimerPaused:      "en pausa",
+}
+
+// French is the quiz in French.
+var French = Messages{
+	Start:            "Appuyez sur entrée pour commencer le quiz...",
+	Problem:          "Question n° %d : %s",
+	ChooseOne:        "Choisissez-en une : ",
+	ChooseMany:       "Choisissez toutes les bonnes réponses (par ex. A,C) : ",
+	Hint:             "Indice %d/%d : %s",
+	NoHints:          "Plus d'indices pour cette question.",
+	TimeUp:           "Temps écoulé !",
+	ProblemTimeUp:    "Temps écoulé pour cette question !",
+	Correct:          "Correct !",
+	PartlyRight:      "En partie juste, la réponse est %s.",
+	Wrong:            "Faux, la réponse est %s.",
+	Paused:           "En pause. Appuyez sur entrée pour continuer...",
+	PausedUnsaveable: "En pause, mais ce quiz choisit ses questions au fur et à mesure et ne peut pas être sauvegardé.\nAppuyez sur entrée pour continuer...",
+	PausedFailed:     "En pause, mais la sauvegarde a échoué : %v\nAppuyez sur entrée pour continuer...",
+	PausedSaved:      "En pause et sauvegardé. Appuyez sur entrée pour continuer, ou quittez et reprenez plus tard...",
+	Missed:           "Manquées :",
+	YouAnswered:      "vous avez répondu %s, la réponse est %s",
+	TimedOut:         "(temps écoulé)",
+	Nothing:          "(rien)",
+	Sealed:           "(scellée)",
+	Score:            "Vous avez obtenu %s sur %s.",
+	Interrupted:      "Interrompu !",
+	CarryOnLater:     "Reprenez plus tard avec : quiz -resume %s",
+	CarryingOn:       "Reprise à la question n° %d sur %d.",
+	Skill:            "Niveau estimé : %.0f, environ la difficulté %.1f.",
+	NothingToReview:  "Rien à réviser avant le %s.",
+	Help:             "%s pour un indice, %s pour une pause, Ctrl+C pour arrêter",
+	Incorrect:        "Faux.",
+	Title:            "Quiz",
+	Progress:         "Question %d/%d",
+	RunningScore:     "Score %s/%s",
+	QuizTimer:        "Quiz",
+	ProblemTimer:     "Question",
+	TimeLeft:         "reste %s",
+	TimerPaused:      "en pause",
+}
+
+// German is the quiz in German.
+var German = Messages{
+	Start:            "Drücke Enter, um das Quiz zu starten...",
+	Problem:          "Frage Nr. %d: %s",
+	ChooseOne:        "Wähle eine: ",
+	ChooseMany:       "Wähle alle zutreffenden (z. B. A,C): ",
+	Hint:             "Tipp %d/%d: %s",
+	NoHints:          "Keine weiteren Tipps für diese Frage.",
+	TimeUp:           "Die Zeit ist um!",
+	ProblemTimeUp:    "Die Zeit für diese Frage ist um!",
+	Correct:          "Richtig!",
+	PartlyRight:      "Teilweise richtig, die Antwort ist %s.",
+	Wrong:            "Falsch, die Antwort ist %s.",
+	Paused:           "Pausiert. Drücke Enter, um weiterzumachen...",
+	PausedUnsaveable: "Pausiert, aber dieses Quiz wählt seine Fragen unterwegs aus und kann nicht gespeichert werden.\nDrücke Enter, um weiterzumachen...",
+	PausedFailed:     "Pausiert, aber der Spielstand konnte nicht gespeichert werden: %v\nDrücke Enter, um weiterzumachen...",
+	PausedSaved:      "Pausiert und gespeichert. Drücke Enter, um weiterzumachen, oder beende das Quiz und setze es später fort...",
+	Missed:           "Verpasst:",
+	YouAnswered:      "du hast %s geantwortet, die Antwort ist %s",
+	TimedOut:         "(Zeit abgelaufen)",
+	Nothing:          "(nichts)",
+	Sealed:           "(versiegelt)",
+	Score:            "Du hast %s von %s Punkten erreicht.",
+	Interrupted:      "Abgebrochen!",
+	CarryOnLater:     "Später weitermachen mit: quiz -resume %s",
+	CarryingOn:       "Weiter ab Frage Nr. %d von %d.",
+	Skill:            "Geschätztes Können: %.0f, etwa Schwierigkeit %.1f.",
+	NothingToReview:  "Nichts zu wiederholen bis %s.",
+	Help:             "%s für einen Tipp, %s für eine Pause, Strg+C zum Beenden",
+	Incorrect:        "Falsch.",
+	Title:            "Quiz",
+	Progress:         "Frage %d/%d",
+	RunningScore:     "Punkte %s/%s",
+	QuizTimer:        "Quiz",
+	ProblemTimer:     "Frage",
+	TimeLeft:         "noch %s",
+	TimerPaused:      "pausiert",
+}
+
+// catalog maps languages to the messages in them.
+var catalog = map[language.Tag]Messages{
+	language.English: English,
+	language.Spanish: Spanish,
+	language.French:  French,
+	language.German:  German,
+}
+
+// RegisterMessages adds the messages for a language, replacing any already
+// registered for it.
+func RegisterMessages(tag language.Tag, m Messages) {
+	catalog[tag] = m
+}
+
+// Locales returns the languages there are messages for.
+func Locales() []string {
+	locales := make([]string, 0, len(catalog))
+	for tag := range catalog {
+		locales = append(locales, tag.String())
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// MessagesFor returns the messages for the closest language to locale,
+// which is a BCP 47 tag such as "fr-CA" or a POSIX locale such as
+// "fr_CA.UTF-8" as found in $LANG.
+func MessagesFor(locale string) (Messages, error) {
+	// POSIX locales put an encoding and a modifier after the language
+	name, _, _ := strings.Cut(locale, ".")
+	name, _, _ = strings.Cut(name, "@")
+	tag, err := language.Parse(strings.ReplaceAll(name, "_", "-"))
+	if err != nil {
+		return English, fmt.Errorf("unknown locale %q", locale)
+	}
+
+	// English goes first so that it wins any tie
+	tags := []language.Tag{language.English}
+	for t := range catalog {
+		if t != language.English {
+			tags = append(tags, t)
+		}
+	}
+	_, i, confidence := language.NewMatcher(tags).Match(tag)
+	if confidence == language.No {
+		return English, fmt.Errorf("no messages for %q, expected one of %s", locale, strings.Join(Locales(), ", "))
+	}
+	return catalog[tags[i]], nil
+}
+
+// WithMessages shows the quiz in the language of m instead of English.
+func WithMessages(m Messages) Option {
+	return func(q *Quiz) {
+		q.messages = m
+	}
+}
+
+// WriteMissed lists the problems in the result which were missed, with the
+// expected answer and the explanation when the problem has one.
+func (m Messages) WriteMissed(w io.Writer, r Result) {
+	missed := r.Missed()
+	if len(missed) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n"+m.Missed)
+	for _, a := range missed {
+		given := a.Given
+		if a.TimedOut {
+			given = m.TimedOut
+		} else if given == "" {
+			given = m.Nothing
+		}
+		fmt.Fprintf(w, "  %s\n    %s\n", a.Problem.Question, fmt.Sprintf(m.YouAnswered, given, m.expected(a)))
+		if a.Problem.Explanation != "" {
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(a.Problem.Explanation, "\n", "\n    "))
+		}
+	}
+}
+
+// writeHint writes hint i of the problem.
+func (m Messages) writeHint(w io.Writer, p Problem, i int) {
+	if i < len(p.Hints) {
+		fmt.Fprintf(w, m.Hint+"\n", i+1, len(p.Hints), p.Hints[i])
+	}
+}
+
+// writeFeedback tells the user how they did on a problem in practice mode.
+func (m Messages) writeFeedback(w io.Writer, a Answer) {
+	fmt.Fprintln(w, m.Feedback(a))
+	if !a.Correct && a.Problem.Explanation != "" {
+		fmt.Fprintln(w, a.Problem.Explanation)
+	}
+}
+
+// Feedback says whether an answer was right, along with the expected
+// answer when it wasn't.
+func (m Messages) Feedback(a Answer) string {
+	switch {
+	case a.Correct:
+		return m.Correct
+	case a.Credit > 0:
+		return fmt.Sprintf(m.PartlyRight, m.expected(a))
+	}
+	return fmt.Sprintf(m.Wrong, m.expected(a))
+}
+
+// expected returns the expected answer to show for an answer, with the
+// letters of options as they were shown.
+func (m Messages) expected(a Answer) string {
+	if a.Problem.IsSealed() {
+		return m.Sealed
+	}
+	return a.Expected()
+}
+
+// writeOptions lists the options of a problem under its question.
+func (m Messages) writeOptions(w io.Writer, p Problem, order []int) {
+	for i, o := range order {
+		fmt.Fprintf(w, "  %s) %s\n", choiceLetter(i), p.Options[o])
+	}
+	if p.IsMultiSelect() {
+		fmt.Fprint(w, m.ChooseMany)
+	} else {
+		fmt.Fprint(w, m.ChooseOne)
+	}
+}
@@ -0,0 +1,96 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalization is a set of changes made to both the given and the
+// expected answers before they are matched, so that text which looks the
+// same to the user compares the same.
+type Normalization int
+
+const (
+	// NFC composes characters, so that "é" typed as "e" and a combining
+	// accent matches "é" typed as a single character
+	NFC Normalization = 1 << iota
+	// NFKC also replaces compatibility characters with the ones they
+	// stand for, such as "ﬁ" with "fi" and "²" with "2"
+	NFKC
+	// FoldAccents removes accents, so that "cafe" matches "café"
+	FoldAccents
+	// FoldWidth turns full-width letters and digits into ordinary ones,
+	// so that "１２" matches "12"
+	FoldWidth
+)
+
+// DefaultNormalization is used unless the quiz is given another with
+// WithNormalization.
+const DefaultNormalization = NFC
+
+// normalizationNames maps the names understood by ParseNormalization to
+// the normalizations.
+var normalizationNames = map[string]Normalization{
+	"nfc":     NFC,
+	"nfkc":    NFKC,
+	"accents": FoldAccents,
+	"width":   FoldWidth,
+}
+
+// ParseNormalization parses a comma separated list of normalizations,
+// each one of nfc, nfkc, accents or width, e.g. "nfkc,accents". "none"
+// turns normalization off.
+func ParseNormalization(s string) (Normalization, error) {
+	var n Normalization
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+		flag, ok := normalizationNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown normalization %q, expected nfc, nfkc, accents, width or none", name)
+		}
+		n |= flag
+	}
+	return n, nil
+}
+
+// WithNormalization sets how answers are normalized before they are
+// matched.
+func WithNormalization(n Normalization) Option {
+	return func(q *Quiz) {
+		q.normalization = n
+	}
+}
+
+// Apply returns s normalized.
+func (n Normalization) Apply(s string) string {
+	var t []transform.Transformer
+	if n&FoldWidth != 0 {
+		t = append(t, width.Fold)
+	}
+	if n&FoldAccents != 0 {
+		t = append(t, norm.NFD, runes.Remove(runes.In(unicode.Mn)))
+	}
+	switch {
+	case n&NFKC != 0:
+		t = append(t, norm.NFKC)
+	case n&(NFC|FoldAccents) != 0:
+		t = append(t, norm.NFC)
+	}
+	if len(t) == 0 {
+		return s
+	}
+	out, _, err := transform.String(transform.Chain(t...), s)
+	if err != nil {
+		return s
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package quiz
+
+import "testing"
+
+func TestParseNormalization(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Normalization
+		err  bool
+	}{
+		{"", 0, false},
+		{"none", 0, false},
+		{"nfc", NFC, false},
+		{"NFKC", NFKC, false},
+		{"nfkc, accents", NFKC | FoldAccents, false},
+		{"nfc,accents,width", NFC | FoldAccents | FoldWidth, false},
+		{"width,", FoldWidth, false},
+		{"nfd", 0, true},
+		{"nfc,bogus", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNormalization(tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseNormalization(%q) error = %v, want error %v", tt.s, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseNormalization(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizationApply(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Normalization
+		s    string
+		want string
+	}{
+		{"none", 0, "cafe\u0301", "cafe\u0301"},
+		{"nfc composes", NFC, "cafe\u0301", "café"},
+		{"nfc keeps compatibility characters", NFC, "ﬁx²", "ﬁx²"},
+		{"nfkc", NFKC, "ﬁx²", "fix2"},
+		{"nfkc composes", NFKC, "cafe\u0301", "café"},
+		{"nfkc folds width", NFKC, "１２ＡＢ", "12AB"},
+		{"accents", FoldAccents, "Crème brûlée", "Creme brulee"},
+		{"accents decomposed", FoldAccents, "cafe\u0301", "cafe"},
+		{"accents keep other letters", FoldAccents, "Straße, Øre", "Straße, Øre"},
+		{"width", FoldWidth, "１２ＡＢ", "12AB"},
+		{"width leaves half width", FoldWidth, "12AB", "12AB"},
+		{"width folds half-width katakana", FoldWidth, "ｶﾀｶﾅ", "カタカナ"},
+		{"width and accents", FoldWidth | FoldAccents, "ｃａｆé", "cafe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Apply(tt.s); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessagesFor(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   Messages
+		err    bool
+	}{
+		{"en", English, false},
+		{"es", Spanish, false},
+		{"es-MX", Spanish, false},
+		{"fr_CA.UTF-8", French, false},
+		{"de_DE@euro", German, false},
+		{"en-GB", English, false},
+		// Languages without messages fall back to English
+		{"ja", English, true},
+		{"not a locale", English, true},
+	}
+	for _, tt := range tests {
+		got, err := MessagesFor(tt.locale)
+		if (err != nil) != tt.err {
+			t.Errorf("MessagesFor(%q) error = %v, want error %v", tt.locale, err, tt.err)
+		}
+		if got.TimeUp != tt.want.TimeUp {
+			t.Errorf("MessagesFor(%q) says %q, want %q", tt.locale, got.TimeUp, tt.want.TimeUp)
+		}
+	}
+}
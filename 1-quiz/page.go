@@ -0,0 +1,180 @@
+package quiz
+
+import "html/template"
+
+func init() {
+	pageTpl = template.Must(template.New("").Parse(defaultPageTemplate))
+}
+
+var pageTpl *template.Template
+
+// defaultPageTemplate is a single page which takes the quiz through the
+// JSON API. The server keeps the time, the countdown on the page is only
+// there to show it.
+var defaultPageTemplate = `
+    <!DOCTYPE html>
+    <html>
+    <head>
+        <title>Quiz</title>
+        <meta charset="UTF-8" />
+        <meta name="viewport" content="width=device-width, initial-scale=1" />
+    </head>
+    <body>
+        <section class="page">
+            <h1>Quiz</h1>
+            <div id="start">
+                <button id="start-button">Start the quiz</button>
+            </div>
+            <form id="question" hidden>
+                <p class="status"><span id="progress"></span> <span id="timer"></span></p>
+                <h2 id="text"></h2>
+                <ul id="options"></ul>
+                <input id="answer" autocomplete="off" />
+                <button type="submit">Answer</button>
+            </form>
+            <div id="result" hidden>
+                <h2 id="score"></h2>
+                <ul id="answers"></ul>
+                <button id="again">Try again</button>
+            </div>
+            <style>
+                body {
+                    font-family: helvetica, arial;
+                }
+                h1 {
+                    text-align: center;
+                    position: relative;
+                }
+                .page {
+                    width: 80%;
+                    max-width: 500px;
+                    margin: auto;
+                    margin-top: 40px;
+                    margin-bottom: 40px;
+                    padding: 80px;
+                    background: #FFFCF6;
+                    border: 1px solid #eee;
+                    box-shadow: 0 10px 6px -6px #777;
+                }
+                ul {
+                    list-style: none;
+                    padding: 0;
+                }
+                li {
+                    padding-top: 10px;
+                }
+                .status {
+                    color: #777;
+                }
+                .correct {
+                    color: #3a7d44;
+                }
+                .incorrect {
+                    color: #b33a3a;
+                }
+            </style>
+        </section>
+        <script>
+            var session = null;
+            var question = null;
+            var ticker = null;
+
+            function show(id) {
+                ["start", "question", "result"].forEach(function (s) {
+                    document.getElementById(s).hidden = s !== id;
+                });
+            }
+
+            function api(method, path, body) {
+                return fetch("/api/sessions" + path, {
+                    method: method,
+                    headers: {"Content-Type": "application/json"},
+                    body: body ? JSON.stringify(body) : undefined
+                }).then(function (res) { return res.json(); });
+            }
+
+            function start() {
+                api("POST", "").then(function (s) {
+                    session = s;
+                    next();
+                });
+            }
+
+            function next() {
+                api("GET", "/" + session.id + "/question").then(function (q) {
+                    if (q.done) {
+                        return finish();
+                    }
+                    question = q;
+                    question.fetched = Date.now();
+                    document.getElementById("progress").textContent =
+                        "Problem " + q.number + "/" + q.total;
+                    document.getElementById("text").textContent = q.question;
+                    var options = document.getElementById("options");
+                    options.innerHTML = "";
+                    (q.options || []).forEach(function (o, i) {
+                        var li = document.createElement("li");
+                        li.textContent = String.fromCharCode(65 + i) + ") " + o;
+                        options.appendChild(li);
+                    });
+                    var answer = document.getElementById("answer");
+                    answer.value = "";
+                    answer.placeholder = q.options ? (q.multi_select ? "e.g. A,C" : "e.g. A") : "";
+                    show("question");
+                    answer.focus();
+                    tick();
+                });
+            }
+
+            // tick shows the time left and asks the server for the next
+            // question once it runs out
+            function tick() {
+                clearTimeout(ticker);
+                var left = [question.time_left, question.problem_time_left].filter(function (t) {
+                    return t !== undefined;
+                });
+                if (left.length === 0) {
+                    document.getElementById("timer").textContent = "";
+                    return;
+                }
+                var remaining = Math.min.apply(null, left) - (Date.now() - question.fetched) / 1000;
+                if (remaining <= 0) {
+                    return next();
+                }
+                document.getElementById("timer").textContent = Math.ceil(remaining) + "s left";
+                ticker = setTimeout(tick, 250);
+            }
+
+            function submit(e) {
+                e.preventDefault();
+                clearTimeout(ticker);
+                api("POST", "/" + session.id + "/answer", {
+                    number: question.number,
+                    answer: document.getElementById("answer").value
+                }).then(next);
+            }
+
+            function finish() {
+                clearTimeout(ticker);
+                api("GET", "/" + session.id + "/result").then(function (r) {
+                    document.getElementById("score").textContent =
+                        (r.timed_out ? "Time's up! " : "") +
+                        "You scored " + r.points + " out of " + r.max_points + ".";
+                    var answers = document.getElementById("answers");
+                    answers.innerHTML = "";
+                    r.answers.forEach(function (a) {
+                        var li = document.createElement("li");
+                        li.className = a.correct ? "correct" : "incorrect";
+                        li.textContent = a.question + " = " + (a.timed_out ? "(timed out)" : a.given);
+                        answers.appendChild(li);
+                    });
+                    show("result");
+                });
+            }
+
+            document.getElementById("start-button").onclick = start;
+            document.getElementById("again").onclick = start;
+            document.getElementById("question").onsubmit = submit;
+        </script>
+    </body>
+    </html>`
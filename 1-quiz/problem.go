@@ -0,0 +1,90 @@
+package quiz
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Problem is a single question in the quiz along with the answer
+// that is expected from the user.
+type Problem struct {
+	// ID optionally identifies the problem within its bank
+	ID       string
+	Question string
+	Answer   string
+	// Alternatives are other answers which are also accepted
+	Alternatives []string
+	// Options makes the problem multiple choice. The options are shown
+	// with the letters A, B, C... and Answer holds the letters of the
+	// correct options, e.g. "A" or "A,C".
+	Options []string
+	// MultiSelect lets more than one option be picked even when only one
+	// of them is correct. Problems with several correct options are
+	// always multi select.
+	MultiSelect bool
+	// Hints are revealed one at a time when the user asks for them, at a
+	// penalty for each
+	Hints []string
+	Tags  []string
+	// Difficulty is a level where higher is harder. Zero means unrated.
+	Difficulty int
+	// Explanation is shown when the problem is missed
+	Explanation string
+	// Weight is how many points the problem is worth. Zero means 1.
+	Weight float64
+	// TimeLimit overrides the per-problem time limit of the quiz for this
+	// problem. Zero means the quiz setting is used.
+	TimeLimit time.Duration
+	// Matcher checks answers to this problem. When nil the quiz matcher
+	// is used.
+	Matcher Matcher
+	// Source is where the problem was loaded from, such as the file it
+	// is in when a directory of banks is loaded
+	Source string
+	// Reversed is set on problems made by Reverse, which show the answer
+	// of the problem in the bank and expect its question
+	Reversed bool
+}
+
+// Key identifies the problem within its bank, using its ID when it has one
+// and the question otherwise. Reversed problems have keys of their own, so
+// that they are scheduled for review apart from the problem in the bank.
+func (p Problem) Key() string {
+	if p.Reversed {
+		return reverseKey(p.ID, p.Answer)
+	}
+	if p.ID != "" {
+		return p.ID
+	}
+	return p.Question
+}
+
+// reverseKey is the key of a reversed problem with the given ID and
+// question in the bank.
+func reverseKey(id, question string) string {
+	if id != "" {
+		return "reverse:" + id
+	}
+	return "reverse:" + question
+}
+
+// Worth returns how many points the problem is worth.
+func (p Problem) Worth() float64 {
+	if p.Weight > 0 {
+		return p.Weight
+	}
+	return 1
+}
+
+// AcceptedAnswers returns the answer followed by any alternatives.
+func (p Problem) AcceptedAnswers() []string {
+	return append([]string{p.Answer}, p.Alternatives...)
+}
+
+// Shuffle shuffles the problems in place using the provided random source.
+func Shuffle(problems []Problem, rnd *rand.Rand) {
+	rnd.Shuffle(
+		len(problems),
+		func(i, j int) { problems[i], problems[j] = problems[j], problems[i] },
+	)
+}
@@ -0,0 +1,390 @@
+package quiz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLimit is the default time limit for the whole quiz.
+const DefaultTimeLimit = 30 * time.Second
+
+// Quiz holds a list of problems and the settings used to ask them.
+// Create one with New and call Run to start a session.
+type Quiz struct {
+	problems         []Problem
+	timeLimit        time.Duration
+	problemTimeLimit time.Duration
+	matcher          Matcher
+	reverseMatcher   Matcher
+	maxTypingRate    float64
+	passphrase       string
+	optionRand       *rand.Rand
+	clock            Clock
+	hintPenalty      float64
+	practice         bool
+	strategy         Strategy
+	saveCheckpoint   func(c *Checkpoint) error
+	resume           *resumeState
+	display          Display
+	normalization    Normalization
+	messages         Messages
+	// input is what the last run read its answers with, which the next run
+	// on the same reader carries on from
+	input *input
+}
+
+// Option configures a Quiz using the functional option pattern.
+type Option func(q *Quiz)
+
+// WithTimeLimit sets the time limit for the whole quiz. A limit of zero
+// means the quiz can take as long as it needs.
+func WithTimeLimit(d time.Duration) Option {
+	return func(q *Quiz) {
+		q.timeLimit = d
+	}
+}
+
+// WithProblemTimeLimit gives every problem its own deadline. When it runs
+// out the problem is marked as timed out and the quiz moves on to the next
+// one. Problems with their own TimeLimit use that instead.
+func WithProblemTimeLimit(d time.Duration) Option {
+	return func(q *Quiz) {
+		q.problemTimeLimit = d
+	}
+}
+
+// WithMatcher sets the matcher used for problems which don't have their
+// own.
+func WithMatcher(m Matcher) Option {
+	return func(q *Quiz) {
+		q.matcher = m
+	}
+}
+
+// WithShuffledOptions shows the options of multiple choice problems in a
+// random order, independently of the order of the problems themselves.
+func WithShuffledOptions(rnd *rand.Rand) Option {
+	return func(q *Quiz) {
+		q.optionRand = rnd
+	}
+}
+
+// WithClock replaces the clock used for the timer and answer latencies.
+func WithClock(c Clock) Option {
+	return func(q *Quiz) {
+		q.clock = c
+	}
+}
+
+// New creates a quiz which will ask the given problems in order, unless it
+// is given another Strategy.
+func New(problems []Problem, opts ...Option) *Quiz {
+	q := Quiz{
+		problems:       problems,
+		timeLimit:      DefaultTimeLimit,
+		matcher:        DefaultMatcher,
+		reverseMatcher: DefaultReverseMatcher,
+		maxTypingRate:  DefaultMaxTypingRate,
+		clock:          RealClock{},
+		hintPenalty:    DefaultHintPenalty,
+		normalization:  DefaultNormalization,
+		messages:       English,
+	}
+	for _, opt := range opts {
+		opt(&q)
+	}
+	if q.strategy == nil {
+		q.strategy = InOrder(problems)
+	}
+	return &q
+}
+
+// Problems returns the problems that the quiz will ask, in the order they
+// will be asked when the Strategy knows it up front.
+func (q *Quiz) Problems() []Problem {
+	if s, ok := q.strategy.(orderedStrategy); ok {
+		return s.Order()
+	}
+	return q.problems
+}
+
+// Run asks every problem by writing prompts to w and reading answers line
+// by line from r. The timer only starts once the user presses enter.
+// Run returns early when the time limit for the whole quiz runs out, in
+// which case the returned Result is marked as timed out. Problems which run
+// out of their own time are marked as timed out and the quiz carries on.
+func (q *Quiz) Run(r io.Reader, w io.Writer) (Result, error) {
+	return q.RunContext(context.Background(), r, w)
+}
+
+// RunContext is like Run but stops asking problems once ctx is done, such
+// as when the user presses Ctrl+C. It then returns the problems answered
+// so far along with ctx.Err(), so that a partial score can still be shown.
+func (q *Quiz) RunContext(ctx context.Context, r io.Reader, w io.Writer) (Result, error) {
+	result, err := q.run(ctx, r, w)
+	result.MaxPoints = q.maxPoints(result)
+	if err != nil && ctx.Err() != nil {
+		result.Ended = EndStopped
+	}
+	return result, err
+}
+
+func (q *Quiz) run(ctx context.Context, r io.Reader, w io.Writer) (Result, error) {
+	result := Result{Total: q.strategy.Len()}
+	display := q.display
+	if display == nil {
+		display = lineDisplay{w: w, practice: q.practice, messages: q.messages}
+	}
+
+	// A resumed quiz carries on from where it was paused
+	problemHints := 0
+	var elapsed, problemElapsed time.Duration
+	var orders [][]int
+	if q.resume != nil {
+		problemHints = q.resume.hints
+		elapsed = q.resume.elapsed
+		problemElapsed = q.resume.problemElapsed
+		result.Answers = append(result.Answers, q.resume.answers...)
+		orders = q.resume.orders
+	}
+
+	// One goroutine reads every line for the start prompt and the
+	// answers, so that the quiz isn't stuck waiting for the user to enter
+	// an answer when time runs out
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	in := q.startInput(ctx, r)
+
+	// Wait for user to press enter before starting the quiz timer
+	display.Show(Event{Kind: EventStart, Total: result.Total, TimeLimit: q.timeLimit})
+	if err := waitForEnter(ctx, in); err != nil {
+		return result, err
+	}
+
+	// Timer sends a message on its channel once the time limit runs out.
+	// Without a limit the channel is nil, which is never ready, so the
+	// quiz only ends once every problem has been asked.
+	timer := newCountdown(q.clock, q.timeLimit, elapsed)
+
+	for {
+		problem, ok := q.strategy.Next(result.Answers)
+		if !ok {
+			break
+		}
+		// The options of a resumed problem are shown in the same order as
+		// before it was paused
+		i := len(result.Answers)
+		for len(orders) <= i {
+			orders = append(orders, nil)
+		}
+		if orders[i] == nil && problem.IsMultipleChoice() {
+			orders[i] = optionOrder(problem, q.optionRand)
+		}
+
+		// The timer for this problem also measures how long the answer
+		// took, not counting any time spent paused
+		problemTimer := newCountdown(q.clock, q.problemLimit(problem), problemElapsed)
+		problemElapsed = 0
+
+		hints := 0
+		e := Event{
+			Number:           i + 1,
+			Total:            result.Total,
+			Problem:          problem,
+			Order:            orders[i],
+			TimeLimit:        q.timeLimit,
+			ProblemTimeLimit: problemTimer.limit,
+		}
+		show := func(kind EventKind) {
+			e.Kind = kind
+			e.Hints = hints
+			e.Points = result.Points()
+			e.Possible = 0
+			for _, a := range result.Answers {
+				e.Possible += a.Problem.Worth()
+			}
+			e.Deadline = timer.deadline()
+			e.ProblemDeadline = problemTimer.deadline()
+			display.Show(e)
+		}
+
+		for hints < problemHints {
+			hints++
+			show(EventHint)
+		}
+		problemHints = 0
+		show(EventAsk)
+
+		for answered := false; !answered; {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+
+			// Listen for message on timer channel
+			case <-timer.C:
+				result.Answers = append(result.Answers, Answer{
+					Problem:   problem,
+					Order:     orders[i],
+					HintsUsed: hints,
+					Latency:   problemTimer.spent(),
+					TimedOut:  true,
+				})
+				result.TimedOut = true
+				result.Ended = EndTimeUp
+				show(EventTimeUp)
+				return result, nil
+
+			// Listen for message on the timer for this problem only
+			case <-problemTimer.C:
+				e.Answer = Answer{
+					Problem:   problem,
+					Order:     orders[i],
+					HintsUsed: hints,
+					Latency:   problemTimer.spent(),
+					TimedOut:  true,
+				}
+				result.Answers = append(result.Answers, e.Answer)
+				show(EventProblemTimeUp)
+				in.drain()
+				answered = true
+
+			// Listen for answer on answer channel. Once the input has
+			// ended every remaining problem is answered with nothing.
+			case answer, ok := <-in.lines:
+				if !ok && in.err != nil {
+					return result, in.err
+				}
+				given := strings.TrimSpace(answer.text)
+
+				if given == HintCommand {
+					if hints < len(problem.Hints) {
+						hints++
+						show(EventHint)
+					} else {
+						show(EventNoHints)
+					}
+					show(EventAsk)
+					continue
+				}
+
+				if given == PauseCommand {
+					timer.stop()
+					problemTimer.stop()
+					c := q.checkpoint(orders, result, timer.spent(), problemTimer.spent(), hints)
+					e.Message = q.save(c)
+					show(EventPause)
+					e.Message = ""
+					if err := waitForEnter(ctx, in); err != nil {
+						return result, err
+					}
+					timer.start()
+					problemTimer.start()
+					show(EventAsk)
+					continue
+				}
+
+				credit := q.grade(problem, given, orders[i])
+				latency := problemTimer.spent()
+				e.Answer = Answer{
+					Problem:    problem,
+					Order:      orders[i],
+					Given:      given,
+					Correct:    credit == 1,
+					Credit:     credit,
+					HintsUsed:  hints,
+					Points:     q.score(problem, credit, hints),
+					Latency:    latency,
+					Keystrokes: answer.keys,
+					Pasted:     q.pasted(given, answer.keys, latency),
+				}
+				result.Answers = append(result.Answers, e.Answer)
+				show(EventAnswer)
+				answered = true
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// waitForEnter waits for the user to press enter.
+func waitForEnter(ctx context.Context, in *input) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case _, ok := <-in.lines:
+		if !ok && in.err != nil {
+			return in.err
+		}
+	}
+	return nil
+}
+
+// save saves the checkpoint of a paused quiz, if the quiz has somewhere to
+// save it, and returns what to tell the user. c is nil when the quiz can't
+// be saved.
+func (q *Quiz) save(c *Checkpoint) string {
+	if q.saveCheckpoint == nil {
+		return q.messages.Paused
+	}
+	if c == nil {
+		return q.messages.PausedUnsaveable
+	}
+	if err := q.saveCheckpoint(c); err != nil {
+		return fmt.Sprintf(q.messages.PausedFailed, err)
+	}
+	return q.messages.PausedSaved
+}
+
+// grade returns the credit for the given answer to the problem. order is
+// the order the options of a multiple choice problem were shown in.
+func (q *Quiz) grade(p Problem, given string, order []int) float64 {
+	given = q.normalization.Apply(given)
+	if p.IsSealed() {
+		return q.gradeSealed(p, given, order)
+	}
+	return q.gradeNormalized(p, given, order)
+}
+
+// gradeNormalized is grade for an answer which has already been
+// normalized.
+func (q *Quiz) gradeNormalized(p Problem, given string, order []int) float64 {
+	if p.IsMultipleChoice() {
+		return gradeChoices(p, given, order)
+	}
+	if q.check(p, given) {
+		return 1
+	}
+	return 0
+}
+
+// check reports whether the given answer, which has already been
+// normalized, is correct for the problem.
+func (q *Quiz) check(p Problem, given string) bool {
+	matcher := q.matcher
+	if p.Reversed {
+		matcher = q.reverseMatcher
+	}
+	if p.Matcher != nil {
+		matcher = p.Matcher
+	}
+	for _, answer := range p.AcceptedAnswers() {
+		if matcher.Match(given, q.normalization.Apply(answer)) {
+			return true
+		}
+	}
+	return false
+}
+
+// problemLimit returns the time allowed for a single problem, or zero if
+// the problem has no deadline of its own.
+func (q *Quiz) problemLimit(p Problem) time.Duration {
+	if p.TimeLimit > 0 {
+		return p.TimeLimit
+	}
+	return q.problemTimeLimit
+}
@@ -0,0 +1,275 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock which only moves when the test advances it.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	// expired makes every timer fire as soon as it is started
+	expired bool
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if c.expired || d <= 0 {
+		t.c <- c.now
+		return t.c
+	}
+	c.timers = append(c.timers, t)
+	return t.c
+}
+
+// Advance moves the clock on by d, firing any timers which are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+// blockingReader returns the lines in text and then blocks until release
+// is closed, like a user who has stopped typing.
+type blockingReader struct {
+	r       io.Reader
+	release chan struct{}
+}
+
+func newBlockingReader(text string) *blockingReader {
+	return &blockingReader{r: strings.NewReader(text), release: make(chan struct{})}
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	<-b.release
+	return 0, io.EOF
+}
+
+var testProblems = []Problem{
+	{Question: "5+5", Answer: "10"},
+	{Question: "capital of France", Answer: "Paris"},
+	{Question: "7+3", Answer: "10"},
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		given []string
+		right []bool
+		score int
+	}{
+		{
+			name:  "all correct",
+			input: "\n10\nparis\n10\n",
+			given: []string{"10", "paris", "10"},
+			right: []bool{true, true, true},
+			score: 3,
+		},
+		{
+			name:  "wrong answers",
+			input: "\n11\nLondon\n10\n",
+			given: []string{"11", "London", "10"},
+			right: []bool{false, false, true},
+			score: 1,
+		},
+		{
+			name:  "surrounding whitespace is ignored",
+			input: "\n  10 \r\n\tParis\n10\n",
+			given: []string{"10", "Paris", "10"},
+			right: []bool{true, true, true},
+			score: 3,
+		},
+		{
+			name:  "input ends early",
+			input: "\n10\n",
+			given: []string{"10", "", ""},
+			right: []bool{true, false, false},
+			score: 1,
+		},
+		{
+			name:  "input ends before the start",
+			input: "",
+			given: []string{"", "", ""},
+			right: []bool{false, false, false},
+			score: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := New(testProblems, WithClock(newFakeClock()))
+			var out strings.Builder
+			result, err := q.Run(strings.NewReader(tt.input), &out)
+			if err != nil {
+				t.Fatalf("Run returned %v", err)
+			}
+			if result.TimedOut {
+				t.Error("result is timed out")
+			}
+			if result.Total != len(testProblems) {
+				t.Errorf("Total = %d, want %d", result.Total, len(testProblems))
+			}
+			if len(result.Answers) != len(tt.given) {
+				t.Fatalf("got %d answers, want %d", len(result.Answers), len(tt.given))
+			}
+			for i, a := range result.Answers {
+				if a.Given != tt.given[i] || a.Correct != tt.right[i] {
+					t.Errorf("answer %d = %q, correct %v, want %q, correct %v", i+1, a.Given, a.Correct, tt.given[i], tt.right[i])
+				}
+			}
+			if got := result.Score(); got != tt.score {
+				t.Errorf("Score() = %d, want %d", got, tt.score)
+			}
+			for i, p := range testProblems {
+				if want := "Problem #" + string(rune('1'+i)) + ": " + p.Question + " = "; !strings.Contains(out.String(), want) {
+					t.Errorf("output %q doesn't ask %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	// The user starts the quiz and then never answers
+	r := newBlockingReader("\n")
+	defer close(r.release)
+	clock := newFakeClock()
+	clock.expired = true
+	q := New(testProblems, WithClock(clock), WithTimeLimit(time.Minute))
+
+	var out strings.Builder
+	result, err := q.Run(r, &out)
+	if err != nil {
+		t.Fatalf("Run returned %v", err)
+	}
+	if !result.TimedOut || result.Ended != EndTimeUp {
+		t.Errorf("result timed out %v and ended %q, want timed out and %q", result.TimedOut, result.Ended, EndTimeUp)
+	}
+	if len(result.Answers) != 1 || !result.Answers[0].TimedOut || result.Answers[0].Correct {
+		t.Fatalf("answers = %+v, want the first problem timed out", result.Answers)
+	}
+	if result.Score() != 0 || result.Total != len(testProblems) {
+		t.Errorf("scored %d of %d, want 0 of %d", result.Score(), result.Total, len(testProblems))
+	}
+	if !strings.Contains(out.String(), English.TimeUp) {
+		t.Errorf("output %q doesn't say time is up", out.String())
+	}
+}
+
+func TestRunStopped(t *testing.T) {
+	// The quiz is stopped, such as with Ctrl+C, while waiting for an answer
+	r := newBlockingReader("\n")
+	defer close(r.release)
+	clock := newFakeClock()
+	q := New(testProblems, WithClock(clock), WithTimeLimit(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		// Wait for the quiz timer to start before stopping it
+		for {
+			clock.mu.Lock()
+			started := len(clock.timers) > 0
+			clock.mu.Unlock()
+			if started {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	result, err := q.RunContext(ctx, r, io.Discard)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext returned %v, want %v", err, context.Canceled)
+	}
+	if result.TimedOut || result.Ended != EndStopped {
+		t.Errorf("result timed out %v and ended %q, want %q", result.TimedOut, result.Ended, EndStopped)
+	}
+	if rec := NewRecord(result, "bank", "", clock.Now()); rec.EndReason() != EndStopped {
+		t.Errorf("EndReason() = %q, want %q", rec.EndReason(), EndStopped)
+	}
+}
+
+func TestRunProblemTimeout(t *testing.T) {
+	// The first problem runs out of time, then the rest are answered
+	r := newBlockingReader("\n")
+	clock := newFakeClock()
+	q := New(testProblems, WithClock(clock), WithTimeLimit(0), WithProblemTimeLimit(10*time.Second))
+
+	done := make(chan Result)
+	go func() {
+		result, err := q.Run(r, io.Discard)
+		if err != nil {
+			t.Errorf("Run returned %v", err)
+		}
+		done <- result
+	}()
+	// Wait for the problem timer to start before running it out
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		clock.mu.Lock()
+		started := len(clock.timers) > 0
+		clock.mu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the problem timer never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(10 * time.Second)
+	close(r.release)
+
+	result := <-done
+	if result.TimedOut {
+		t.Error("the whole quiz is timed out")
+	}
+	if len(result.Answers) != len(testProblems) {
+		t.Fatalf("got %d answers, want %d", len(result.Answers), len(testProblems))
+	}
+	first := result.Answers[0]
+	if !first.TimedOut || first.Latency != 10*time.Second {
+		t.Errorf("first answer = %+v, want timed out after 10s", first)
+	}
+	for _, a := range result.Answers[1:] {
+		if a.TimedOut {
+			t.Errorf("answer to %q timed out", a.Problem.Question)
+		}
+	}
+}
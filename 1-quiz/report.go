@@ -0,0 +1,302 @@
+package quiz
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReportWriter writes a detailed report of a quiz run.
+type ReportWriter func(w io.Writer, rec Record) error
+
+// reportWriters maps report formats to the ReportWriter for them.
+var reportWriters = map[string]ReportWriter{
+	"json":     WriteJSONReport,
+	"csv":      WriteCSVReport,
+	"junit":    WriteJUnitReport,
+	"markdown": WriteMarkdownReport,
+	"md":       WriteMarkdownReport,
+}
+
+// RegisterReportWriter makes WriteReport use the writer for the given
+// format, replacing any writer already registered for it.
+func RegisterReportWriter(format string, rw ReportWriter) {
+	reportWriters[strings.ToLower(format)] = rw
+}
+
+// ReportFormats returns the names of every registered report format.
+func ReportFormats() []string {
+	formats := make([]string, 0, len(reportWriters))
+	for f := range reportWriters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ReportWriterFor returns the writer registered for the format.
+func ReportWriterFor(format string) (ReportWriter, error) {
+	rw, ok := reportWriters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(ReportFormats(), ", "))
+	}
+	return rw, nil
+}
+
+// WriteReport writes a report of the run in the given format.
+func WriteReport(w io.Writer, format string, rec Record) error {
+	rw, err := ReportWriterFor(format)
+	if err != nil {
+		return err
+	}
+	return rw(w, rec)
+}
+
+// WriteJSONReport writes the record as indented JSON, along with how each
+// direction went when problems were asked in reverse.
+func WriteJSONReport(w io.Writer, rec Record) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Record
+		Directions []DirectionSummary `json:"directions,omitempty"`
+	}{rec, rec.Directions()})
+}
+
+// WriteCSVReport writes a row for every answer under a header. When
+// problems were asked in reverse a direction column says which way each
+// one was asked.
+func WriteCSVReport(w io.Writer, rec Record) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"number", "id", "question", "expected", "given", "correct",
+		"credit", "points", "hints", "seconds", "timed_out",
+	}
+	directions := rec.Directions() != nil
+	if directions {
+		header = append(header, "direction")
+	}
+	cw.Write(header)
+	for i, a := range rec.Answers {
+		row := []string{
+			strconv.Itoa(i + 1),
+			a.ID,
+			a.Question,
+			a.Expected,
+			a.Given,
+			strconv.FormatBool(a.Correct),
+			formatFloat(a.Credit),
+			formatFloat(a.Points),
+			strconv.Itoa(a.Hints),
+			formatFloat(a.Latency().Seconds()),
+			strconv.FormatBool(a.TimedOut),
+		}
+		if directions {
+			row = append(row, answerDirection(a).String())
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Props     []junitProperty `xml:"properties>property"`
+	Cases     []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnitReport writes the run as a JUnit XML test suite with a test
+// case for every problem, so it can be shown by CI tools. Missed problems
+// are failures, and problems which were never asked because the quiz
+// ended early are skipped.
+func WriteJUnitReport(w io.Writer, rec Record) error {
+	suite := junitSuite{
+		Name:      rec.Bank,
+		Tests:     rec.Total,
+		Skipped:   rec.Total - len(rec.Answers),
+		Timestamp: rec.Time.UTC().Format("2006-01-02T15:04:05"),
+		Props: []junitProperty{
+			{Name: "user", Value: rec.User},
+			{Name: "points", Value: formatFloat(rec.Points)},
+			{Name: "max_points", Value: formatFloat(rec.Max())},
+		},
+	}
+	if pasted := rec.Pasted(); pasted > 0 {
+		suite.Props = append(suite.Props, junitProperty{Name: "pasted", Value: strconv.Itoa(pasted)})
+	}
+	for _, d := range rec.Directions() {
+		suite.Props = append(suite.Props, junitProperty{Name: d.Direction + "_accuracy", Value: formatFloat(d.Accuracy())})
+	}
+
+	var total time.Duration
+	for i, a := range rec.Answers {
+		total += a.Latency()
+		c := junitCase{
+			Name:      fmt.Sprintf("%d: %s", i+1, a.Question),
+			ClassName: rec.Bank,
+			Time:      formatFloat(a.Latency().Seconds()),
+		}
+		switch {
+		case a.TimedOut:
+			c.Failure = &junitFailure{Message: "ran out of time, expected " + a.Expected, Type: "timeout"}
+		case !a.Correct:
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("expected %s, got %s", a.Expected, a.Given),
+				Type:    "wrong answer",
+			}
+		}
+		if c.Failure != nil {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for i := len(rec.Answers); i < rec.Total; i++ {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      fmt.Sprintf("%d", i+1),
+			ClassName: rec.Bank,
+			Time:      "0",
+			Skipped:   &junitSkipped{Message: notAsked(rec.EndReason())},
+		})
+	}
+	suite.Time = formatFloat(total.Seconds())
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteMarkdownReport writes a summary of the run followed by a table of
+// every answer.
+func WriteMarkdownReport(w io.Writer, rec Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Quiz report: %s\n\n", markdownEscape(rec.Bank))
+	if rec.User != "" {
+		fmt.Fprintf(&b, "- User: %s\n", markdownEscape(rec.User))
+	}
+	fmt.Fprintf(&b, "- Date: %s\n", rec.Time.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "- Score: %s out of %s (%.0f%%)\n", formatFloat(rec.Points), formatFloat(rec.Max()), rec.Accuracy()*100)
+	if skipped := rec.Total - len(rec.Answers); skipped > 0 {
+		fmt.Fprintf(&b, "- Not asked: %d, %s\n", skipped, endedEarly(rec.EndReason()))
+	}
+	if pasted := rec.Pasted(); pasted > 0 {
+		fmt.Fprintf(&b, "- Pasted: %d answers arrived faster than they could be typed\n", pasted)
+	}
+	for _, d := range rec.Directions() {
+		fmt.Fprintf(&b, "- %s: %d of %d right (%.0f%%)\n", strings.ToUpper(d.Direction[:1])+d.Direction[1:], d.Right, d.Asked, d.Accuracy()*100)
+	}
+
+	b.WriteString("\n| # | Question | Expected | Given | Result | Points | Time |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for i, a := range rec.Answers {
+		result := "wrong"
+		switch {
+		case a.TimedOut:
+			result = "timed out"
+		case a.Correct:
+			result = "right"
+		case a.Credit > 0:
+			result = "partly right"
+		}
+		if a.Pasted {
+			result += ", pasted"
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s | %.1fs |\n",
+			i+1,
+			markdownEscape(a.Question),
+			markdownEscape(a.Expected),
+			markdownEscape(a.Given),
+			result,
+			formatFloat(a.Points),
+			a.Latency().Seconds(),
+		)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// notAsked says why a problem wasn't asked.
+func notAsked(reason EndReason) string {
+	switch reason {
+	case EndTimeUp:
+		return "not asked before time ran out"
+	case EndStopped:
+		return "not asked before the quiz was stopped"
+	}
+	return "not asked"
+}
+
+// endedEarly says why the quiz ended before every problem was asked.
+func endedEarly(reason EndReason) string {
+	switch reason {
+	case EndTimeUp:
+		return "time ran out"
+	case EndStopped:
+		return "the quiz was stopped early"
+	}
+	return "the quiz ended early"
+}
+
+// answerDirection returns the direction the answer's problem was asked in.
+func answerDirection(a RecordAnswer) Direction {
+	if a.Reversed {
+		return Reverse
+	}
+	return Forward
+}
+
+// markdownEscape keeps text from breaking out of a table cell.
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
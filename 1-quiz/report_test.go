@@ -0,0 +1,98 @@
+package quiz
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// reportRecord is a run which was stopped with one of its five problems
+// left, with text which has to be escaped in every format.
+func reportRecord() Record {
+	return Record{
+		Time:      time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+		Bank:      "sums & <capitals>",
+		User:      "ada",
+		Total:     5,
+		Points:    1.5,
+		MaxPoints: 4,
+		Ended:     EndStopped,
+		Answers: []RecordAnswer{
+			{ID: "add-1", Question: "5+5", Expected: "10", Given: "10", Correct: true, Credit: 1, Points: 1, LatencyMS: 1500},
+			{
+				Question: `Is "a<b" | "a>b" true?`, Expected: "yes|y", Given: "no | maybe",
+				LatencyMS: 2250, Pasted: true, KeystrokesMS: []int64{1, 1},
+			},
+			{Question: "Pick the primes\nfrom 4, 5 and 7", Expected: "B,C", Given: "B", Credit: 0.5, Points: 0.5, Hints: 1, LatencyMS: 4000},
+			{Question: "capital of France", Expected: "Paris", LatencyMS: 10000, TimedOut: true},
+		},
+	}
+}
+
+func TestReportGolden(t *testing.T) {
+	for _, format := range []string{"json", "csv", "junit", "markdown"} {
+		t.Run(format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteReport(&b, format, reportRecord()); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", "report."+format+".golden")
+			if *update {
+				if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != string(want) {
+				t.Errorf("WriteReport() =\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
+
+func TestReportEnded(t *testing.T) {
+	tests := []struct {
+		name     string
+		timedOut bool
+		ended    EndReason
+		junit    string
+		markdown string
+	}{
+		{"time up", true, EndTimeUp, "not asked before time ran out", "- Not asked: 1, time ran out"},
+		{"stopped", false, EndStopped, "not asked before the quiz was stopped", "- Not asked: 1, the quiz was stopped early"},
+		// Records from before Ended was kept only ended early on time
+		{"old record", true, "", "not asked before time ran out", "- Not asked: 1, time ran out"},
+		{"unknown", false, "", `message="not asked"`, "- Not asked: 1, the quiz ended early"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := Record{
+				Bank: "bank", Total: 2, TimedOut: tt.timedOut, Ended: tt.ended,
+				Answers: []RecordAnswer{{Question: "5+5", Expected: "10", Given: "10", Correct: true, Credit: 1, Points: 1}},
+			}
+			var junit, markdown strings.Builder
+			if err := WriteJUnitReport(&junit, rec); err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteMarkdownReport(&markdown, rec); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(junit.String(), tt.junit) {
+				t.Errorf("the junit report doesn't say %q:\n%s", tt.junit, junit.String())
+			}
+			if !strings.Contains(markdown.String(), tt.markdown) {
+				t.Errorf("the markdown report doesn't say %q:\n%s", tt.markdown, markdown.String())
+			}
+		})
+	}
+}
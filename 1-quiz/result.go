@@ -0,0 +1,99 @@
+package quiz
+
+import "time"
+
+// Answer records what happened when a single problem was asked.
+type Answer struct {
+	Problem Problem
+	// Order is the order the options of a multiple choice problem were
+	// shown in, where Order[i] is the index in Problem.Options shown with
+	// letter i. Given uses the letters as they were shown.
+	Order []int
+	// Given is the answer entered by the user, with surrounding
+	// whitespace removed.
+	Given   string
+	Correct bool
+	// Credit is how much of the problem was answered correctly, from 0 to
+	// 1. It is only between the two for multi select problems which were
+	// partly right.
+	Credit float64
+	// HintsUsed is how many hints were revealed before answering
+	HintsUsed int
+	// Points is what the answer scored, which is the problem's weight
+	// times the credit, less the penalty for any hints.
+	Points float64
+	// Latency is the time between showing the problem and receiving
+	// the answer.
+	Latency  time.Duration
+	TimedOut bool
+	// Keystrokes are the times between the keys pressed for the answer,
+	// when the input could tell
+	Keystrokes []time.Duration
+	// Pasted is set when the answer arrived faster than it could have
+	// been typed
+	Pasted bool
+}
+
+// Expected returns the answer which was expected, with the letters of
+// multiple choice options as they were shown rather than as they are in
+// the bank.
+func (a Answer) Expected() string {
+	return shownChoices(a.Problem, a.Order)
+}
+
+// Result is the outcome of a quiz run. Answers holds an entry for every
+// problem that was asked, in the order they were asked.
+type Result struct {
+	Answers []Answer
+	// Total is the number of problems in the quiz, including any which
+	// were never asked because time ran out.
+	Total int
+	// MaxPoints is what every problem in the quiz is worth together
+	MaxPoints float64
+	TimedOut  bool
+	// Ended is why the quiz ended before every problem was asked, or empty
+	// when it didn't
+	Ended EndReason
+}
+
+// EndReason is why a quiz ended before every problem was asked.
+type EndReason string
+
+const (
+	// EndTimeUp is when the time limit for the whole quiz ran out
+	EndTimeUp EndReason = "time_up"
+	// EndStopped is when the quiz was stopped early, such as with Ctrl+C
+	EndStopped EndReason = "stopped"
+)
+
+// Points returns the points scored in the quiz, which includes partial
+// credit for multi select problems and hint penalties.
+func (r Result) Points() float64 {
+	points := 0.0
+	for _, a := range r.Answers {
+		points += a.Points
+	}
+	return points
+}
+
+// Missed returns the answers which weren't fully correct.
+func (r Result) Missed() []Answer {
+	var missed []Answer
+	for _, a := range r.Answers {
+		if !a.Correct {
+			missed = append(missed, a)
+		}
+	}
+	return missed
+}
+
+// Score returns the number of correctly answered problems.
+func (r Result) Score() int {
+	score := 0
+	for _, a := range r.Answers {
+		if a.Correct {
+			score++
+		}
+	}
+	return score
+}
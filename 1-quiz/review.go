@@ -0,0 +1,240 @@
+package quiz
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// day is the unit SM-2 intervals are counted in
+	day = 24 * time.Hour
+
+	// DefaultEase is the ease factor given to problems on their first
+	// review, and minEase is the lowest it can fall to
+	DefaultEase = 2.5
+	minEase     = 1.3
+
+	// DefaultSlowAnswer is how long a correct answer can take before it
+	// counts as a struggle when grading reviews
+	DefaultSlowAnswer = 10 * time.Second
+)
+
+// Card is the review state of a single problem in a bank.
+type Card struct {
+	Bank string `json:"bank"`
+	Key  string `json:"key"`
+	// Repetitions is the number of reviews in a row which were passed
+	Repetitions int `json:"repetitions"`
+	// IntervalDays is the number of days between the last review and the
+	// next one
+	IntervalDays float64   `json:"interval_days"`
+	Ease         float64   `json:"ease"`
+	Due          time.Time `json:"due"`
+	LastReviewed time.Time `json:"last_reviewed"`
+}
+
+type cardKey struct {
+	bank, key string
+}
+
+// Scheduler decides which problems are due for review using the SM-2
+// spaced repetition algorithm. Problems which are missed or answered
+// slowly come back sooner, while problems which keep being answered
+// quickly are pushed further into the future.
+type Scheduler struct {
+	// SlowAnswer is how long a correct answer can take before it is
+	// graded as hard
+	SlowAnswer time.Duration
+
+	clock Clock
+	cards map[cardKey]*Card
+}
+
+// NewScheduler returns a scheduler with no review history which uses clock
+// to tell when problems are due.
+func NewScheduler(clock Clock) *Scheduler {
+	return &Scheduler{
+		SlowAnswer: DefaultSlowAnswer,
+		clock:      clock,
+		cards:      make(map[cardKey]*Card),
+	}
+}
+
+// LoadScheduler reads the review history saved at path by Save. A file
+// that doesn't exist yet gives a scheduler with no history.
+func LoadScheduler(path string, clock Clock) (*Scheduler, error) {
+	s := NewScheduler(clock)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []Card
+	if err := json.Unmarshal(data, &cards); err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		c := cards[i]
+		s.cards[cardKey{c.Bank, c.Key}] = &c
+	}
+	return s, nil
+}
+
+// Save writes the review history to path.
+func (s *Scheduler) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.Cards(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Cards returns the review state of every problem which has been
+// reviewed, ordered by bank and key.
+func (s *Scheduler) Cards() []Card {
+	cards := make([]Card, 0, len(s.cards))
+	for _, c := range s.cards {
+		cards = append(cards, *c)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].Bank != cards[j].Bank {
+			return cards[i].Bank < cards[j].Bank
+		}
+		return cards[i].Key < cards[j].Key
+	})
+	return cards
+}
+
+// Card returns the review state of a problem from the bank, and whether it
+// has been reviewed before.
+func (s *Scheduler) Card(bank string, p Problem) (Card, bool) {
+	c, ok := s.cards[cardKey{bank, p.Key()}]
+	if !ok {
+		return Card{}, false
+	}
+	return *c, true
+}
+
+// Select chooses which problems from the bank to ask in a review session.
+// Problems which are due are asked first, the most overdue before the
+// rest, followed by problems which have never been reviewed in the order
+// they appear in the bank. At most n problems are chosen, or every
+// problem which is due when n is zero.
+func (s *Scheduler) Select(bank string, problems []Problem, n int) []Problem {
+	now := s.clock.Now()
+
+	type due struct {
+		problem Problem
+		at      time.Time
+	}
+	var overdue []due
+	var unseen []Problem
+	for _, p := range problems {
+		c, ok := s.cards[cardKey{bank, p.Key()}]
+		switch {
+		case !ok:
+			unseen = append(unseen, p)
+		case !c.Due.After(now):
+			overdue = append(overdue, due{p, c.Due})
+		}
+	}
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].at.Before(overdue[j].at)
+	})
+
+	selected := make([]Problem, 0, len(overdue)+len(unseen))
+	for _, d := range overdue {
+		selected = append(selected, d.problem)
+	}
+	selected = append(selected, unseen...)
+	if n > 0 && len(selected) > n {
+		selected = selected[:n]
+	}
+	return selected
+}
+
+// NextDue returns when the next problem from the bank will be due, and
+// false if none of the problems have been reviewed.
+func (s *Scheduler) NextDue(bank string, problems []Problem) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, p := range problems {
+		c, ok := s.cards[cardKey{bank, p.Key()}]
+		if !ok {
+			continue
+		}
+		if !found || c.Due.Before(next) {
+			next, found = c.Due, true
+		}
+	}
+	return next, found
+}
+
+// Grade turns an answer into an SM-2 quality from 0 to 5, where anything
+// below 3 is a failed review.
+func (s *Scheduler) Grade(a Answer) int {
+	switch {
+	case a.TimedOut:
+		return 0
+	case a.Credit == 0:
+		return 1
+	case !a.Correct:
+		// Partly right answers to multi select problems
+		return 2
+	case a.HintsUsed > 0:
+		return 3
+	case a.Latency > s.SlowAnswer:
+		return 3
+	case a.Latency > s.SlowAnswer/2:
+		return 4
+	}
+	return 5
+}
+
+// Review updates the schedule for the problem that was answered.
+func (s *Scheduler) Review(bank string, a Answer) Card {
+	k := cardKey{bank, a.Problem.Key()}
+	c, ok := s.cards[k]
+	if !ok {
+		c = &Card{Bank: bank, Key: k.key, Ease: DefaultEase}
+		s.cards[k] = c
+	}
+
+	quality := s.Grade(a)
+	if quality < 3 {
+		// Start again from the first interval when the review is failed
+		c.Repetitions = 0
+		c.IntervalDays = 1
+	} else {
+		switch c.Repetitions {
+		case 0:
+			c.IntervalDays = 1
+		case 1:
+			c.IntervalDays = 6
+		default:
+			c.IntervalDays *= c.Ease
+		}
+		c.Repetitions++
+	}
+
+	q := float64(5 - quality)
+	c.Ease += 0.1 - q*(0.08+q*0.02)
+	if c.Ease < minEase {
+		c.Ease = minEase
+	}
+
+	now := s.clock.Now()
+	c.LastReviewed = now
+	c.Due = now.Add(time.Duration(c.IntervalDays * float64(day)))
+	return *c
+}
@@ -0,0 +1,164 @@
+package quiz
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	perfect  = Answer{Correct: true, Credit: 1, Latency: time.Second}
+	good     = Answer{Correct: true, Credit: 1, Latency: 6 * time.Second}
+	slow     = Answer{Correct: true, Credit: 1, Latency: 20 * time.Second}
+	hinted   = Answer{Correct: true, Credit: 1, Latency: time.Second, HintsUsed: 1}
+	partly   = Answer{Credit: 0.5}
+	wrong    = Answer{}
+	timedOut = Answer{TimedOut: true}
+)
+
+func TestSchedulerGrade(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer Answer
+		want   int
+	}{
+		{"perfect", perfect, 5},
+		{"good", good, 4},
+		{"slow", slow, 3},
+		{"hinted", hinted, 3},
+		{"partly right", partly, 2},
+		{"wrong", wrong, 1},
+		{"timed out", timedOut, 0},
+	}
+	s := NewScheduler(newFakeClock())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Grade(tt.answer); got != tt.want {
+				t.Errorf("Grade() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerReview(t *testing.T) {
+	// step is the state of the card after each review
+	type step struct {
+		repetitions int
+		interval    float64
+		ease        float64
+	}
+	tests := []struct {
+		name    string
+		answers []Answer
+		want    []step
+	}{
+		{
+			name:    "perfect answers grow the interval by the ease",
+			answers: []Answer{perfect, perfect, perfect, perfect},
+			want:    []step{{1, 1, 2.6}, {2, 6, 2.7}, {3, 16.2, 2.8}, {4, 45.36, 2.9}},
+		},
+		{
+			name:    "good answers keep the ease",
+			answers: []Answer{good, good, good},
+			want:    []step{{1, 1, 2.5}, {2, 6, 2.5}, {3, 15, 2.5}},
+		},
+		{
+			name:    "slow answers lower the ease",
+			answers: []Answer{slow, slow, slow},
+			want:    []step{{1, 1, 2.36}, {2, 6, 2.22}, {3, 13.32, 2.08}},
+		},
+		{
+			name:    "a miss starts the intervals again",
+			answers: []Answer{perfect, perfect, wrong, perfect},
+			want:    []step{{1, 1, 2.6}, {2, 6, 2.7}, {0, 1, 2.16}, {1, 1, 2.26}},
+		},
+		{
+			name:    "a partly right answer fails the review",
+			answers: []Answer{perfect, partly},
+			want:    []step{{1, 1, 2.6}, {0, 1, 2.28}},
+		},
+		{
+			name:    "the ease never falls below the minimum",
+			answers: []Answer{timedOut, timedOut, timedOut},
+			want:    []step{{0, 1, 1.7}, {0, 1, minEase}, {0, 1, minEase}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			s := NewScheduler(clock)
+			p := Problem{Question: "5+5", Answer: "10"}
+			for i, a := range tt.answers {
+				a.Problem = p
+				now := clock.Now()
+				c := s.Review("bank", a)
+				want := tt.want[i]
+				if c.Repetitions != want.repetitions || !near(c.IntervalDays, want.interval) || !near(c.Ease, want.ease) {
+					t.Fatalf("review %d: repetitions %d, interval %v, ease %v, want %d, %v, %v",
+						i+1, c.Repetitions, c.IntervalDays, c.Ease, want.repetitions, want.interval, want.ease)
+				}
+				if !c.LastReviewed.Equal(now) {
+					t.Errorf("review %d: last reviewed %v, want %v", i+1, c.LastReviewed, now)
+				}
+				// Intervals are fractions of days, so the due time can be a
+				// rounding error out
+				due := now.Add(time.Duration(want.interval * float64(day)))
+				if d := c.Due.Sub(due); d < -time.Millisecond || d > time.Millisecond {
+					t.Errorf("review %d: due %v, want %v", i+1, c.Due, due)
+				}
+				// The next review happens when the problem is due
+				clock.Advance(c.Due.Sub(now))
+			}
+		})
+	}
+}
+
+func TestSchedulerSelect(t *testing.T) {
+	clock := newFakeClock()
+	s := NewScheduler(clock)
+	problems := []Problem{
+		{ID: "new"},
+		{ID: "later"},
+		{ID: "due"},
+		{ID: "overdue"},
+		{ID: "unseen"},
+	}
+	now := clock.Now()
+	s.cards[cardKey{"bank", "later"}] = &Card{Due: now.Add(day)}
+	s.cards[cardKey{"bank", "due"}] = &Card{Due: now}
+	s.cards[cardKey{"bank", "overdue"}] = &Card{Due: now.Add(-2 * day)}
+
+	ids := func(problems []Problem) []string {
+		var ids []string
+		for _, p := range problems {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{0, []string{"overdue", "due", "new", "unseen"}},
+		{3, []string{"overdue", "due", "new"}},
+		{1, []string{"overdue"}},
+	}
+	for _, tt := range tests {
+		if got := ids(s.Select("bank", problems, tt.n)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Select(n=%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+
+	next, ok := s.NextDue("bank", problems)
+	if !ok || !next.Equal(now.Add(-2*day)) {
+		t.Errorf("NextDue() = %v, %v, want %v, true", next, ok, now.Add(-2*day))
+	}
+	if _, ok := s.NextDue("other", problems); ok {
+		t.Error("NextDue() found a problem in a bank which was never reviewed")
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
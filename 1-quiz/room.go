@@ -0,0 +1,711 @@
+package quiz
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRoomQuestionTime is how long players have to answer a
+	// problem in a room when it has no time limit of its own
+	DefaultRoomQuestionTime = 20 * time.Second
+	// DefaultRevealTime is how long the answer and leaderboard are shown
+	// before the next problem
+	DefaultRevealTime = 5 * time.Second
+	// DefaultLobbyTimeout is how long a room waits for the host to start
+	// it before it is closed
+	DefaultLobbyTimeout = time.Hour
+	// DefaultRoomLinger is how long a finished room is kept so that
+	// players who reconnect can still see the final leaderboard
+	DefaultRoomLinger = 10 * time.Minute
+
+	// maxRoomPoints is the most a single answer can score. Half of it is
+	// for being right and the other half goes down the longer it takes.
+	maxRoomPoints = 1000
+)
+
+var (
+	// ErrRoomNotFound is returned for unknown room codes.
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrBadToken is returned when a host or player token doesn't belong
+	// to the room.
+	ErrBadToken = errors.New("invalid token")
+	// ErrNameTaken is returned when joining with a name which another
+	// player in the room already has.
+	ErrNameTaken = errors.New("name is already taken")
+	// ErrNotAccepting is returned for answers which arrive when the room
+	// isn't asking a problem, or for a problem other than the current one.
+	ErrNotAccepting = errors.New("not accepting answers for that problem")
+	// ErrAlreadyAnswered is returned when a player answers the same
+	// problem twice.
+	ErrAlreadyAnswered = errors.New("already answered")
+	// ErrRoomStarted is returned when starting a room twice.
+	ErrRoomStarted = errors.New("room has already started")
+)
+
+// Message types sent to players and hosts in a room.
+const (
+	MsgJoined   = "joined"
+	MsgLobby    = "lobby"
+	MsgQuestion = "question"
+	MsgAnswered = "answered"
+	MsgProgress = "progress"
+	MsgReveal   = "reveal"
+	MsgFinished = "finished"
+	MsgError    = "error"
+
+	// Message types sent by hosts and players
+	MsgStart  = "start"
+	MsgAnswer = "answer"
+)
+
+// RoomMessage is a message between a room and the players and host
+// connected to it. Only the fields which make sense for the Type are set.
+type RoomMessage struct {
+	Type        string   `json:"type"`
+	Room        string   `json:"room,omitempty"`
+	Player      string   `json:"player,omitempty"`
+	Token       string   `json:"token,omitempty"`
+	Players     []string `json:"players,omitempty"`
+	Number      int      `json:"number,omitempty"`
+	Total       int      `json:"total,omitempty"`
+	Question    string   `json:"question,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	MultiSelect bool     `json:"multi_select,omitempty"`
+	// TimeLeft is in seconds
+	TimeLeft float64 `json:"time_left,omitempty"`
+	// Answer is the answer given by a player, or the expected answer
+	// when the problem is revealed unless it is sealed
+	Answer      string     `json:"answer,omitempty"`
+	Answered    int        `json:"answered,omitempty"`
+	Leaderboard []Standing `json:"leaderboard,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Standing is a player's place on the leaderboard.
+type Standing struct {
+	Player  string `json:"player"`
+	Score   int    `json:"score"`
+	Correct int    `json:"correct"`
+	// LastPoints and LastLatency are for the problem which was just
+	// revealed. LastLatency is in seconds and is zero when the player
+	// didn't answer.
+	LastPoints  int     `json:"last_points"`
+	LastLatency float64 `json:"last_latency"`
+	Connected   bool    `json:"connected"`
+}
+
+// RoomConn is a connection to a player or host. Send must not block: a
+// connection which can't keep up should drop itself instead of holding up
+// the rest of the room.
+type RoomConn interface {
+	Send(msg RoomMessage)
+	Close()
+}
+
+// RoomOption configures a RoomManager.
+type RoomOption func(m *RoomManager)
+
+// WithRoomQuestionTime sets how long players have for problems without a
+// time limit of their own.
+func WithRoomQuestionTime(d time.Duration) RoomOption {
+	return func(m *RoomManager) {
+		m.questionTime = d
+	}
+}
+
+// WithRevealTime sets how long answers are shown before the next problem.
+func WithRevealTime(d time.Duration) RoomOption {
+	return func(m *RoomManager) {
+		m.revealTime = d
+	}
+}
+
+// WithRoomClock replaces the clock used by rooms.
+func WithRoomClock(c Clock) RoomOption {
+	return func(m *RoomManager) {
+		m.clock = c
+	}
+}
+
+// WithRoomLinger sets how long finished rooms are kept.
+func WithRoomLinger(d time.Duration) RoomOption {
+	return func(m *RoomManager) {
+		m.linger = d
+	}
+}
+
+// RoomManager runs live quiz rooms, where a host pushes every problem to
+// all players at the same time and a leaderboard rewards answers which are
+// both right and fast. Every room runs in its own goroutine, which stops
+// when the room finishes or the manager is shut down.
+type RoomManager struct {
+	newQuiz      func() *Quiz
+	questionTime time.Duration
+	revealTime   time.Duration
+	lobbyTimeout time.Duration
+	linger       time.Duration
+	clock        Clock
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	rooms map[string]*Room
+}
+
+// NewRoomManager returns a manager which calls newQuiz for the problems of
+// every new room.
+func NewRoomManager(newQuiz func() *Quiz, opts ...RoomOption) *RoomManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := RoomManager{
+		newQuiz:      newQuiz,
+		questionTime: DefaultRoomQuestionTime,
+		revealTime:   DefaultRevealTime,
+		lobbyTimeout: DefaultLobbyTimeout,
+		linger:       DefaultRoomLinger,
+		clock:        RealClock{},
+		ctx:          ctx,
+		cancel:       cancel,
+		rooms:        make(map[string]*Room),
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return &m
+}
+
+// Create opens a new room and returns it. The host token from the room is
+// needed to start it.
+func (m *RoomManager) Create() (*Room, error) {
+	hostToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	q := m.newQuiz()
+	r := &Room{
+		quiz:         q,
+		hostToken:    hostToken,
+		questionTime: m.questionTime,
+		revealTime:   m.revealTime,
+		clock:        m.clock,
+		players:      make(map[string]*roomPlayer),
+		current:      -1,
+		started:      make(chan struct{}),
+		allAnswered:  make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx.Err() != nil {
+		return nil, errors.New("room manager is shut down")
+	}
+	for {
+		code, err := roomCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m.rooms[code]; !ok {
+			r.Code = code
+			break
+		}
+	}
+	m.rooms[r.Code] = r
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		r.run(m.ctx, m.lobbyTimeout)
+		// Keep the finished room around for a while for players who
+		// reconnect, then throw it away
+		select {
+		case <-m.clock.After(m.linger):
+		case <-m.ctx.Done():
+		}
+		r.closeConns()
+		m.mu.Lock()
+		delete(m.rooms, r.Code)
+		m.mu.Unlock()
+	}()
+	return r, nil
+}
+
+// Get returns the room with the given code.
+func (m *RoomManager) Get(code string) (*Room, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.rooms[strings.ToUpper(code)]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	return r, nil
+}
+
+// Shutdown stops every room and waits for their goroutines to finish.
+func (m *RoomManager) Shutdown() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Room is a single live quiz.
+type Room struct {
+	// Code is the short code players use to join
+	Code string
+
+	quiz         *Quiz
+	hostToken    string
+	questionTime time.Duration
+	revealTime   time.Duration
+	clock        Clock
+
+	// started is closed when the host starts the room, allAnswered gets a
+	// value when every connected player has answered the current problem
+	// and done is closed once the room has finished
+	started     chan struct{}
+	allAnswered chan struct{}
+	done        chan struct{}
+
+	mu        sync.Mutex
+	hosts     []RoomConn
+	players   map[string]*roomPlayer
+	order     []*roomPlayer
+	isStarted bool
+	// current is the index of the problem being asked, or -1 when between
+	// problems
+	current  int
+	asked    time.Time
+	deadline time.Time
+	options  []int
+	finished bool
+}
+
+type roomPlayer struct {
+	name    string
+	token   string
+	conn    RoomConn
+	score   int
+	correct int
+	// answers holds the answer to each problem, by index
+	answers map[int]Answer
+	points  map[int]int
+}
+
+// HostToken returns the token the host uses to control the room.
+func (r *Room) HostToken() string {
+	return r.hostToken
+}
+
+// Done is closed when the room has finished.
+func (r *Room) Done() <-chan struct{} {
+	return r.done
+}
+
+// AttachHost connects a host to the room. Hosts see everything players
+// see, plus the lobby and how many players have answered.
+func (r *Room) AttachHost(token string, conn RoomConn) error {
+	if token != r.hostToken {
+		return ErrBadToken
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts = append(r.hosts, conn)
+	conn.Send(RoomMessage{Type: MsgLobby, Room: r.Code, Players: r.playerNames()})
+	if r.finished {
+		conn.Send(RoomMessage{Type: MsgFinished, Room: r.Code, Leaderboard: r.leaderboard(-1)})
+	}
+	return nil
+}
+
+// DetachHost disconnects a host.
+func (r *Room) DetachHost(conn RoomConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, h := range r.hosts {
+		if h == conn {
+			r.hosts = append(r.hosts[:i], r.hosts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Join adds a player to the room and connects them. To reconnect, pass
+// the token from the earlier join instead of an empty one, in which case
+// the name is ignored. It returns the player's token.
+func (r *Room) Join(name, token string, conn RoomConn) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var p *roomPlayer
+	if token != "" {
+		p = r.players[token]
+		if p == nil {
+			return "", ErrBadToken
+		}
+		if p.conn != nil {
+			// The old connection was replaced, e.g. by a page reload
+			p.conn.Close()
+		}
+	} else {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", errors.New("name is required")
+		}
+		for _, other := range r.order {
+			if strings.EqualFold(other.name, name) {
+				return "", ErrNameTaken
+			}
+		}
+		var err error
+		token, err = randomToken()
+		if err != nil {
+			return "", err
+		}
+		p = &roomPlayer{
+			name:    name,
+			token:   token,
+			answers: make(map[int]Answer),
+			points:  make(map[int]int),
+		}
+		r.players[token] = p
+		r.order = append(r.order, p)
+	}
+	p.conn = conn
+
+	conn.Send(RoomMessage{Type: MsgJoined, Room: r.Code, Player: p.name, Token: p.token})
+	r.broadcastHosts(RoomMessage{Type: MsgLobby, Room: r.Code, Players: r.playerNames()})
+
+	// Catch the player up with whatever is happening in the room
+	switch {
+	case r.finished:
+		conn.Send(RoomMessage{Type: MsgFinished, Room: r.Code, Leaderboard: r.leaderboard(-1)})
+	case r.current >= 0 && r.isStarted:
+		if _, answered := p.answers[r.current]; !answered {
+			conn.Send(r.questionMessage())
+		}
+	}
+	return p.token, nil
+}
+
+// Leave marks a player as disconnected. They stay on the leaderboard and
+// can reconnect with their token.
+func (r *Room) Leave(token string, conn RoomConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p := r.players[token]; p != nil && p.conn == conn {
+		p.conn = nil
+		r.checkAllAnswered()
+	}
+}
+
+// Start begins asking problems. Only the host can start the room.
+func (r *Room) Start(token string) error {
+	if token != r.hostToken {
+		return ErrBadToken
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.isStarted {
+		return ErrRoomStarted
+	}
+	r.isStarted = true
+	close(r.started)
+	return nil
+}
+
+// Answer records a player's answer to the problem with the given number.
+func (r *Room) Answer(token string, number int, given string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.players[token]
+	if p == nil {
+		return ErrBadToken
+	}
+	if r.current < 0 || number != r.current+1 {
+		return ErrNotAccepting
+	}
+	if _, ok := p.answers[r.current]; ok {
+		return ErrAlreadyAnswered
+	}
+	now := r.clock.Now()
+	if !now.Before(r.deadline) {
+		return ErrNotAccepting
+	}
+
+	problem := r.quiz.Problems()[r.current]
+	given = strings.TrimSpace(given)
+	credit := r.quiz.grade(problem, given, r.options)
+	a := Answer{
+		Problem: problem,
+		Order:   r.options,
+		Given:   given,
+		Correct: credit == 1,
+		Credit:  credit,
+		Points:  r.quiz.score(problem, credit, 0),
+		Latency: now.Sub(r.asked),
+	}
+	p.answers[r.current] = a
+	points := roomPoints(a, r.deadline.Sub(r.asked))
+	p.points[r.current] = points
+	p.score += points
+	if a.Correct {
+		p.correct++
+	}
+
+	if p.conn != nil {
+		p.conn.Send(RoomMessage{Type: MsgAnswered, Room: r.Code, Number: number, Answer: given})
+	}
+	r.broadcastHosts(RoomMessage{
+		Type:     MsgProgress,
+		Room:     r.Code,
+		Number:   number,
+		Answered: r.answeredCount(),
+		Total:    len(r.order),
+	})
+	r.checkAllAnswered()
+	return nil
+}
+
+// Leaderboard returns the current standings, best first.
+func (r *Room) Leaderboard() []Standing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leaderboard(-1)
+}
+
+// roomPoints scores an answer. Right answers get at least half of the
+// points, and the rest goes down linearly until the time runs out.
+// Partial credit scales the points down and the problem's weight scales
+// them up or down.
+func roomPoints(a Answer, limit time.Duration) int {
+	if a.Credit == 0 || a.TimedOut {
+		return 0
+	}
+	speed := 1 - float64(a.Latency)/float64(limit)
+	if speed < 0 {
+		speed = 0
+	}
+	return int(math.Round(a.Problem.Worth() * a.Credit * maxRoomPoints / 2 * (1 + speed)))
+}
+
+// run asks every problem in turn. It returns when the room is finished,
+// or when ctx is cancelled.
+func (r *Room) run(ctx context.Context, lobbyTimeout time.Duration) {
+	defer close(r.done)
+
+	select {
+	case <-r.started:
+	case <-r.clock.After(lobbyTimeout):
+		r.finish()
+		return
+	case <-ctx.Done():
+		r.finish()
+		return
+	}
+
+	for i, problem := range r.quiz.Problems() {
+		limit := r.quiz.problemLimit(problem)
+		if limit <= 0 {
+			limit = r.questionTime
+		}
+
+		r.mu.Lock()
+		r.current = i
+		r.asked = r.clock.Now()
+		r.deadline = r.asked.Add(limit)
+		r.options = nil
+		if problem.IsMultipleChoice() {
+			r.options = optionOrder(problem, r.quiz.optionRand)
+		}
+		// Drop a signal left over from the last problem
+		select {
+		case <-r.allAnswered:
+		default:
+		}
+		r.broadcast(r.questionMessage())
+		r.mu.Unlock()
+
+		select {
+		case <-r.clock.After(limit):
+		case <-r.allAnswered:
+		case <-ctx.Done():
+			r.finish()
+			return
+		}
+
+		r.mu.Lock()
+		r.current = -1
+		reveal := RoomMessage{
+			Type:        MsgReveal,
+			Room:        r.Code,
+			Number:      i + 1,
+			Total:       len(r.quiz.Problems()),
+			Answer:      revealed(Answer{Problem: problem, Order: r.options}),
+			Leaderboard: r.leaderboard(i),
+		}
+		r.broadcast(reveal)
+		r.mu.Unlock()
+
+		if i == len(r.quiz.Problems())-1 {
+			break
+		}
+		select {
+		case <-r.clock.After(r.revealTime):
+		case <-ctx.Done():
+			r.finish()
+			return
+		}
+	}
+	r.finish()
+}
+
+// finish ends the room and sends everyone the final leaderboard.
+func (r *Room) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = -1
+	r.finished = true
+	r.broadcast(RoomMessage{Type: MsgFinished, Room: r.Code, Leaderboard: r.leaderboard(-1)})
+}
+
+// closeConns disconnects everyone from the room.
+func (r *Room) closeConns() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.order {
+		if p.conn != nil {
+			p.conn.Close()
+			p.conn = nil
+		}
+	}
+	for _, h := range r.hosts {
+		h.Close()
+	}
+	r.hosts = nil
+}
+
+// questionMessage describes the current problem. r.mu must be held.
+func (r *Room) questionMessage() RoomMessage {
+	problem := r.quiz.Problems()[r.current]
+	msg := RoomMessage{
+		Type:        MsgQuestion,
+		Room:        r.Code,
+		Number:      r.current + 1,
+		Total:       len(r.quiz.Problems()),
+		Question:    problem.Question,
+		MultiSelect: problem.IsMultiSelect(),
+		TimeLeft:    r.deadline.Sub(r.clock.Now()).Seconds(),
+	}
+	for _, o := range r.options {
+		msg.Options = append(msg.Options, problem.Options[o])
+	}
+	return msg
+}
+
+// leaderboard returns the standings, with the last answer taken from the
+// problem at index last if it is not negative. r.mu must be held.
+func (r *Room) leaderboard(last int) []Standing {
+	standings := make([]Standing, len(r.order))
+	for i, p := range r.order {
+		standings[i] = Standing{
+			Player:    p.name,
+			Score:     p.score,
+			Correct:   p.correct,
+			Connected: p.conn != nil,
+		}
+		if a, ok := p.answers[last]; ok {
+			standings[i].LastPoints = p.points[last]
+			standings[i].LastLatency = a.Latency.Seconds()
+		}
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Score > standings[j].Score
+	})
+	return standings
+}
+
+func (r *Room) playerNames() []string {
+	names := make([]string, len(r.order))
+	for i, p := range r.order {
+		names[i] = p.name
+	}
+	return names
+}
+
+func (r *Room) answeredCount() int {
+	n := 0
+	for _, p := range r.order {
+		if _, ok := p.answers[r.current]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// checkAllAnswered lets the room move on early once every connected player
+// has answered. r.mu must be held.
+func (r *Room) checkAllAnswered() {
+	if r.current < 0 {
+		return
+	}
+	connected := 0
+	for _, p := range r.order {
+		if p.conn == nil {
+			continue
+		}
+		connected++
+		if _, ok := p.answers[r.current]; !ok {
+			return
+		}
+	}
+	if connected == 0 {
+		return
+	}
+	select {
+	case r.allAnswered <- struct{}{}:
+	default:
+	}
+}
+
+// broadcast sends msg to every connected player and host. r.mu must be
+// held.
+func (r *Room) broadcast(msg RoomMessage) {
+	for _, p := range r.order {
+		if p.conn != nil {
+			p.conn.Send(msg)
+		}
+	}
+	r.broadcastHosts(msg)
+}
+
+func (r *Room) broadcastHosts(msg RoomMessage) {
+	for _, h := range r.hosts {
+		h.Send(msg)
+	}
+}
+
+// roomCodeLetters leaves out letters which are easy to mix up with
+// numbers.
+const roomCodeLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// roomCode returns a short code which is easy to read out to a room.
+func roomCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("couldn't make a room code: %w", err)
+	}
+	for i := range b {
+		b[i] = roomCodeLetters[int(b[i])%len(roomCodeLetters)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,255 @@
+package quiz
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsSendBuffer is how many messages can wait to be written to a
+	// websocket before the connection is dropped as too slow
+	wsSendBuffer = 64
+	wsWriteWait  = 10 * time.Second
+	// wsPongWait is how long a connection can go without a pong before it
+	// is treated as gone, and pings are sent a bit more often than that
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsConn is a RoomConn over a websocket. Messages are queued and written
+// by their own goroutine so that Send never blocks the room.
+type wsConn struct {
+	ws *websocket.Conn
+
+	mu     sync.Mutex
+	out    chan RoomMessage
+	closed bool
+}
+
+func newWSConn(ws *websocket.Conn) *wsConn {
+	c := &wsConn{ws: ws, out: make(chan RoomMessage, wsSendBuffer)}
+	go c.writeLoop()
+	return c
+}
+
+func (c *wsConn) Send(msg RoomMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.out <- msg:
+	default:
+		// Too slow to keep up, so drop it. The player can reconnect.
+		c.closeLocked()
+	}
+}
+
+func (c *wsConn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *wsConn) closeLocked() {
+	if !c.closed {
+		c.closed = true
+		close(c.out)
+	}
+}
+
+// writeLoop writes queued messages and keeps the connection alive with
+// pings. It closes the websocket once the queue is closed, which also ends
+// the read loop.
+func (c *wsConn) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.ws.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.out:
+			c.ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				c.ws.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.ws.WriteJSON(msg); err != nil {
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			c.ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop passes every message from the websocket to handle until the
+// connection is closed.
+func (c *wsConn) readLoop(handle func(msg RoomMessage)) {
+	c.ws.SetReadLimit(wsMaxMessage)
+	c.ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		var msg RoomMessage
+		if err := c.ws.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("websocket: %v", err)
+			}
+			return
+		}
+		handle(msg)
+	}
+}
+
+// NewRoomHandler returns an http.Handler for live quiz rooms:
+//
+//	POST /api/rooms                                   open a room, returns its code and host token
+//	GET  /api/rooms/{code}                            get the players and leaderboard
+//	GET  /ws/rooms/{code}/host?token={host token}     websocket for the host, who sends {"type": "start"}
+//	GET  /ws/rooms/{code}?name={name}[&token={token}] websocket for a player, who sends
+//	                                                  {"type": "answer", "number": 1, "answer": "10"}
+//
+// Players who lose their connection can reconnect with the token from the
+// "joined" message to keep their place on the leaderboard.
+func NewRoomHandler(m *RoomManager) http.Handler {
+	h := roomHandler{m}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/rooms", h.create)
+	mux.HandleFunc("/api/rooms/", h.status)
+	mux.HandleFunc("/ws/rooms/", h.connect)
+	return mux
+}
+
+type roomHandler struct {
+	m *RoomManager
+}
+
+type roomResponse struct {
+	Code        string     `json:"code"`
+	HostToken   string     `json:"host_token,omitempty"`
+	Leaderboard []Standing `json:"leaderboard"`
+}
+
+func (h roomHandler) create(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(rw, http.MethodPost)
+		return
+	}
+	room, err := h.m.Create()
+	if err != nil {
+		log.Printf("%v", err)
+		writeError(rw, http.StatusInternalServerError, "couldn't open a room")
+		return
+	}
+	writeJSON(rw, http.StatusCreated, roomResponse{
+		Code:        room.Code,
+		HostToken:   room.HostToken(),
+		Leaderboard: []Standing{},
+	})
+}
+
+func (h roomHandler) status(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(rw, http.MethodGet)
+		return
+	}
+	room, err := h.m.Get(strings.TrimPrefix(r.URL.Path, "/api/rooms/"))
+	if err != nil {
+		writeError(rw, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(rw, http.StatusOK, roomResponse{Code: room.Code, Leaderboard: room.Leaderboard()})
+}
+
+// connect upgrades to a websocket for either the host or a player.
+func (h roomHandler) connect(rw http.ResponseWriter, r *http.Request) {
+	// "/ws/rooms/ABCDE/host" => ["ABCDE", "host"]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/ws/rooms/"), "/")
+	isHost := len(parts) == 2 && parts[1] == "host"
+	if len(parts) > 2 || (len(parts) == 2 && !isHost) {
+		http.NotFound(rw, r)
+		return
+	}
+	room, err := h.m.Get(parts[0])
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if isHost && token != room.HostToken() {
+		http.Error(rw, ErrBadToken.Error(), http.StatusForbidden)
+		return
+	}
+
+	ws, err := upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		return
+	}
+	conn := newWSConn(ws)
+	defer conn.Close()
+
+	if isHost {
+		h.serveHost(room, token, conn)
+	} else {
+		h.servePlayer(room, r.URL.Query().Get("name"), token, conn)
+	}
+}
+
+func (h roomHandler) serveHost(room *Room, token string, conn *wsConn) {
+	if err := room.AttachHost(token, conn); err != nil {
+		conn.Send(RoomMessage{Type: MsgError, Error: err.Error()})
+		return
+	}
+	defer room.DetachHost(conn)
+
+	conn.readLoop(func(msg RoomMessage) {
+		if msg.Type != MsgStart {
+			conn.Send(RoomMessage{Type: MsgError, Error: "hosts can only send " + MsgStart})
+			return
+		}
+		if err := room.Start(token); err != nil {
+			conn.Send(RoomMessage{Type: MsgError, Error: err.Error()})
+		}
+	})
+}
+
+func (h roomHandler) servePlayer(room *Room, name, token string, conn *wsConn) {
+	token, err := room.Join(name, token, conn)
+	if err != nil {
+		conn.Send(RoomMessage{Type: MsgError, Error: err.Error()})
+		return
+	}
+	defer room.Leave(token, conn)
+
+	conn.readLoop(func(msg RoomMessage) {
+		if msg.Type != MsgAnswer {
+			conn.Send(RoomMessage{Type: MsgError, Error: "players can only send " + MsgAnswer})
+			return
+		}
+		if err := room.Answer(token, msg.Number, msg.Answer); err != nil {
+			conn.Send(RoomMessage{Type: MsgError, Number: msg.Number, Error: err.Error()})
+		}
+	})
+}
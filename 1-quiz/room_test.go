@@ -0,0 +1,213 @@
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestRoomManyPlayers plays through a room with many players connected at
+// once over websockets, dropping and reconnecting some of them along the
+// way. Every player should see every problem and the final leaderboard,
+// and nothing should be left running afterwards. Run it with -race.
+func TestRoomManyPlayers(t *testing.T) {
+	const (
+		players  = 50
+		accuracy = 0.7
+		drop     = 0.1
+	)
+	problems := []Problem{
+		{Question: "5+5", Answer: "10"},
+		{Question: "capital of France", Answer: "Paris"},
+		{Question: "Pick the primes", Options: []string{"4", "5", "7"}, Answer: "B,C"},
+	}
+	answers := map[string]string{"5+5": "10", "capital of France": "paris", "Pick the primes": "B,C"}
+
+	goroutines := runtime.NumGoroutine()
+	// Options aren't shuffled so that the players can pick the right
+	// letters
+	rooms := NewRoomManager(
+		func() *Quiz { return New(problems) },
+		WithRoomQuestionTime(time.Second),
+		WithRevealTime(50*time.Millisecond),
+		WithRoomLinger(time.Minute),
+	)
+	server := httptest.NewServer(NewRoomHandler(rooms))
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	res, err := http.Post(server.URL+"/api/rooms", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var room struct {
+		Code      string `json:"code"`
+		HostToken string `json:"host_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&room); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	host, _, err := websocket.DefaultDialer.Dial(
+		fmt.Sprintf("%s/ws/rooms/%s/host?token=%s", wsURL, room.Code, room.HostToken), nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every player plays in its own goroutine and reports what it saw
+	var wg sync.WaitGroup
+	reports := make([]simReport, players)
+	rnd := rand.New(rand.NewSource(1))
+	for i := range reports {
+		wg.Add(1)
+		seed := rnd.Int63()
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = simulatePlayer(simPlayer{
+				url:      fmt.Sprintf("%s/ws/rooms/%s", wsURL, room.Code),
+				name:     fmt.Sprintf("player-%03d", i+1),
+				answers:  answers,
+				accuracy: accuracy,
+				drop:     drop,
+				rnd:      rand.New(rand.NewSource(seed)),
+			})
+		}(i)
+	}
+
+	// Start once everyone is in the lobby
+	var final RoomMessage
+	for {
+		var msg RoomMessage
+		if err := host.ReadJSON(&msg); err != nil {
+			t.Fatalf("host: %v", err)
+		}
+		if msg.Type == MsgLobby && len(msg.Players) == players {
+			if err := host.WriteJSON(RoomMessage{Type: MsgStart}); err != nil {
+				t.Fatalf("host: %v", err)
+			}
+		}
+		if msg.Type == MsgFinished {
+			final = msg
+			break
+		}
+	}
+	wg.Wait()
+	closeWS(host)
+
+	reconnects := 0
+	for _, r := range reports {
+		if r.err != nil {
+			t.Errorf("%s: %v", r.name, r.err)
+		} else if r.questions != len(problems) {
+			t.Errorf("%s saw %d of %d problems", r.name, r.questions, len(problems))
+		}
+		reconnects += r.reconnects
+	}
+	if len(final.Leaderboard) != players {
+		t.Errorf("the leaderboard has %d players, want %d", len(final.Leaderboard), players)
+	}
+	t.Logf("%d players, %d problems, %d reconnects", players, len(problems), reconnects)
+
+	// Everything should wind down once the server and rooms are stopped
+	rooms.Shutdown()
+	server.Close()
+	waitForGoroutines(t, goroutines)
+}
+
+type simPlayer struct {
+	url      string
+	name     string
+	answers  map[string]string
+	accuracy float64
+	drop     float64
+	rnd      *rand.Rand
+}
+
+type simReport struct {
+	name       string
+	questions  int
+	reconnects int
+	err        error
+}
+
+// simulatePlayer joins the room and answers every problem after a random
+// delay, until the room finishes.
+func simulatePlayer(p simPlayer) simReport {
+	report := simReport{name: p.name}
+	seen := make(map[int]bool)
+	token := ""
+	for {
+		url := p.url + "?name=" + p.name
+		if token != "" {
+			url = p.url + "?token=" + token
+		}
+		ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			report.err = err
+			return report
+		}
+
+		reconnect := false
+		for !reconnect {
+			var msg RoomMessage
+			if err := ws.ReadJSON(&msg); err != nil {
+				closeWS(ws)
+				report.err = err
+				return report
+			}
+
+			switch msg.Type {
+			case MsgJoined:
+				token = msg.Token
+			case MsgQuestion:
+				// A reconnect catches up with the problem which was already
+				// answered
+				if seen[msg.Number] {
+					continue
+				}
+				seen[msg.Number] = true
+				report.questions++
+				answer := "wrong"
+				if p.rnd.Float64() < p.accuracy {
+					answer = p.answers[msg.Question]
+				}
+				delay := time.Duration(p.rnd.Float64() * msg.TimeLeft * 0.8 * float64(time.Second))
+				time.Sleep(delay)
+				err := ws.WriteJSON(RoomMessage{Type: MsgAnswer, Number: msg.Number, Answer: answer})
+				if err != nil {
+					closeWS(ws)
+					report.err = err
+					return report
+				}
+				reconnect = p.rnd.Float64() < p.drop
+			case MsgFinished:
+				closeWS(ws)
+				return report
+			case MsgError:
+				closeWS(ws)
+				report.err = fmt.Errorf("room error: %s", msg.Error)
+				return report
+			}
+		}
+		closeWS(ws)
+		report.reconnects++
+	}
+}
+
+// closeWS closes a websocket cleanly, as a browser would when the page is
+// left.
+func closeWS(ws *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+	ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	ws.Close()
+}
@@ -0,0 +1,367 @@
+package quiz
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Sealed answers are kept in the Answer and Alternatives of a problem in
+// place of the answers themselves, so that any bank format can hold them:
+//
+//	sealed:hash:<fold>:<iterations>:<salt>:<hash>
+//	sealed:aes:<iterations>:<salt>:<nonce and ciphertext>
+//
+// Hashed answers are checked by hashing the given answer the same way, and
+// fold is exact or nocase depending on the matcher the problem was sealed
+// with. Encrypted answers need the passphrase the bank was sealed with.
+// Binary values are base64 encoded.
+const sealPrefix = "sealed:"
+
+// sealIterations is how many rounds of PBKDF2 sealed answers are hashed
+// with, and passphrases are turned into keys with. It makes guessing
+// answers slow without making checking them noticeably so.
+const sealIterations = 50000
+
+// sealSaltSize is the size of the random salt of each sealed answer.
+const sealSaltSize = 16
+
+var sealEncoding = base64.RawURLEncoding
+
+// ErrNoPassphrase is returned when answers which were encrypted are
+// checked without a passphrase.
+var ErrNoPassphrase = errors.New("the bank's answers are encrypted, a passphrase is needed to check them")
+
+// SealOptions configure how Seal seals the answers of a problem.
+type SealOptions struct {
+	// Matcher is used for problems without one of their own. When nil it
+	// is DefaultMatcher.
+	Matcher Matcher
+	// Normalization is applied to answers before they are hashed. The
+	// sealed bank has to be run with the same normalization.
+	Normalization Normalization
+	// Passphrase encrypts the answers which can't be hashed. Problems
+	// with such answers can't be sealed without one.
+	Passphrase string
+}
+
+// Seal returns the problem with its answers sealed so that the bank can be
+// handed out without giving them away. Answers matched exactly or without
+// regard to case are replaced with salted hashes. Any other answers, such
+// as numbers, patterns and the options of multiple choice problems, have
+// to be compared with the answer itself, so they are encrypted with the
+// passphrase instead. Problems which are already sealed are returned as
+// they are.
+//
+// Hashing only slows guessing down. Anyone with the bank can hash every
+// likely answer offline until one matches, which takes moments for short
+// answers, yes or no answers and small numbers. Such answers are better
+// sealed with a matcher other than exact or nocase, so that they are
+// encrypted, or the bank kept private.
+func Seal(p Problem, opts SealOptions) (Problem, error) {
+	if p.IsSealed() {
+		return p, nil
+	}
+	if opts.Matcher == nil {
+		opts.Matcher = DefaultMatcher
+	}
+
+	seal := func(answer string) (string, error) {
+		return sealEncrypted(answer, opts.Passphrase)
+	}
+	if p.IsMultipleChoice() {
+		// Whether more than one option can be picked depends on the
+		// answer, which is about to be hidden
+		p.MultiSelect = p.IsMultiSelect()
+	} else {
+		// The matcher is kept with the problem so that it is checked the
+		// same way whatever the quiz is run with
+		if p.Matcher == nil {
+			p.Matcher = opts.Matcher
+		}
+		if fold, ok := hashFold(p.Matcher); ok {
+			seal = func(answer string) (string, error) {
+				return sealHashed(opts.Normalization.Apply(strings.TrimSpace(answer)), fold)
+			}
+		}
+	}
+
+	answers := p.AcceptedAnswers()
+	for i, answer := range answers {
+		sealed, err := seal(answer)
+		if err != nil {
+			return p, err
+		}
+		answers[i] = sealed
+	}
+	p.Answer, p.Alternatives = answers[0], answers[1:]
+	return p, nil
+}
+
+// IsSealed reports whether the answers of the problem were sealed by Seal.
+func (p Problem) IsSealed() bool {
+	_, ok := parseSealed(p.Answer)
+	return ok
+}
+
+// IsEncrypted reports whether the answers of the problem were sealed with
+// a passphrase, which is then needed to check them.
+func (p Problem) IsEncrypted() bool {
+	v, ok := parseSealed(p.Answer)
+	return ok && v.kind == "aes"
+}
+
+// WithPassphrase sets the passphrase which opens encrypted answers.
+func WithPassphrase(passphrase string) Option {
+	return func(q *Quiz) {
+		q.passphrase = passphrase
+	}
+}
+
+// CheckPassphrase checks that the passphrase opens the answers of the
+// problems, so that a wrong one is found before the quiz starts rather
+// than by every answer being marked wrong. It returns ErrNoPassphrase
+// when answers are encrypted and the passphrase is empty.
+func CheckPassphrase(problems []Problem, passphrase string) error {
+	for _, p := range problems {
+		if !p.IsEncrypted() {
+			continue
+		}
+		if passphrase == "" {
+			return ErrNoPassphrase
+		}
+		_, err := unseal(p, passphrase)
+		return err
+	}
+	return nil
+}
+
+// gradeSealed grades an answer, which has already been normalized, to a
+// sealed problem. Hashed answers are compared with the hash of the given
+// answer. Encrypted answers are opened and graded like those of any other
+// problem, but never leave the quiz.
+func (q *Quiz) gradeSealed(p Problem, given string, order []int) float64 {
+	if !p.IsEncrypted() {
+		for _, answer := range p.AcceptedAnswers() {
+			if v, ok := parseSealed(answer); ok && v.matches(given) {
+				return 1
+			}
+		}
+		return 0
+	}
+	opened, err := unseal(p, q.passphrase)
+	if err != nil {
+		return 0
+	}
+	return q.gradeNormalized(opened, given, order)
+}
+
+// unseal returns the problem with its encrypted answers decrypted.
+func unseal(p Problem, passphrase string) (Problem, error) {
+	if passphrase == "" {
+		return p, ErrNoPassphrase
+	}
+	answers := p.AcceptedAnswers()
+	for i, answer := range answers {
+		v, ok := parseSealed(answer)
+		if !ok || v.kind != "aes" {
+			continue
+		}
+		opened, err := v.open(passphrase)
+		if err != nil {
+			return p, err
+		}
+		answers[i] = opened
+	}
+	p.Answer, p.Alternatives = answers[0], answers[1:]
+	return p, nil
+}
+
+// revealed returns the answer to show once the problem is over, which is
+// nothing for sealed problems.
+func revealed(a Answer) string {
+	if a.Problem.IsSealed() {
+		return ""
+	}
+	return a.Expected()
+}
+
+// sealedExpected is recorded in the history in place of the expected
+// answer to a sealed problem.
+const sealedExpected = "(sealed)"
+
+// recordedExpected returns the expected answer to keep in the history,
+// which is a placeholder for sealed problems.
+func recordedExpected(a Answer) string {
+	if a.Problem.IsSealed() {
+		return sealedExpected
+	}
+	return a.Expected()
+}
+
+// hashFold returns how the answers checked by m are folded before they
+// are hashed, and false when m doesn't compare answers as plain text.
+func hashFold(m Matcher) (string, bool) {
+	switch m.(type) {
+	case Exact:
+		return "exact", true
+	case CaseInsensitive:
+		return "nocase", true
+	}
+	return "", false
+}
+
+// foldCase maps every rune to the smallest rune it is equal to when case
+// is ignored, so that two strings fold the same exactly when
+// strings.EqualFold reports them equal.
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		least := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < least {
+				least = f
+			}
+		}
+		return least
+	}, s)
+}
+
+// sealedValue is a sealed answer taken apart.
+type sealedValue struct {
+	// kind is hash or aes
+	kind       string
+	fold       string
+	iterations int
+	salt       []byte
+	data       []byte
+}
+
+// parseSealed parses a sealed answer, reporting false when s isn't one.
+func parseSealed(s string) (sealedValue, bool) {
+	if !strings.HasPrefix(s, sealPrefix) {
+		return sealedValue{}, false
+	}
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), sealPrefix), ":")
+	var v sealedValue
+	v.kind = fields[0]
+	switch {
+	case v.kind == "hash" && len(fields) == 5:
+		v.fold = fields[1]
+		if v.fold != "exact" && v.fold != "nocase" {
+			return v, false
+		}
+		fields = fields[2:]
+	case v.kind == "aes" && len(fields) == 4:
+		fields = fields[1:]
+	default:
+		return v, false
+	}
+	var err error
+	if v.iterations, err = strconv.Atoi(fields[0]); err != nil || v.iterations < 1 {
+		return v, false
+	}
+	if v.salt, err = sealEncoding.DecodeString(fields[1]); err != nil {
+		return v, false
+	}
+	if v.data, err = sealEncoding.DecodeString(fields[2]); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+func (v sealedValue) String() string {
+	fields := []string{v.kind}
+	if v.kind == "hash" {
+		fields = append(fields, v.fold)
+	}
+	fields = append(fields, strconv.Itoa(v.iterations), sealEncoding.EncodeToString(v.salt), sealEncoding.EncodeToString(v.data))
+	return sealPrefix + strings.Join(fields, ":")
+}
+
+// matches reports whether the given answer hashes to the hashed answer.
+func (v sealedValue) matches(given string) bool {
+	if v.kind != "hash" {
+		return false
+	}
+	if v.fold == "nocase" {
+		given = foldCase(given)
+	}
+	sum := pbkdf2.Key([]byte(given), v.salt, v.iterations, sha256.Size, sha256.New)
+	return hmac.Equal(sum, v.data)
+}
+
+// open decrypts an encrypted answer.
+func (v sealedValue) open(passphrase string) (string, error) {
+	aead, err := sealCipher(passphrase, v.salt, v.iterations)
+	if err != nil {
+		return "", err
+	}
+	if len(v.data) < aead.NonceSize() {
+		return "", errors.New("the sealed answer is too short")
+	}
+	nonce, ciphertext := v.data[:aead.NonceSize()], v.data[aead.NonceSize():]
+	answer, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("the passphrase doesn't open the bank's answers")
+	}
+	return string(answer), nil
+}
+
+func sealHashed(answer, fold string) (string, error) {
+	v := sealedValue{kind: "hash", fold: fold, iterations: sealIterations}
+	if err := v.newSalt(); err != nil {
+		return "", err
+	}
+	if fold == "nocase" {
+		answer = foldCase(answer)
+	}
+	v.data = pbkdf2.Key([]byte(answer), v.salt, v.iterations, sha256.Size, sha256.New)
+	return v.String(), nil
+}
+
+func sealEncrypted(answer, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", errors.New("the answers can't be hashed, so they need a passphrase to be sealed with")
+	}
+	v := sealedValue{kind: "aes", iterations: sealIterations}
+	if err := v.newSalt(); err != nil {
+		return "", err
+	}
+	aead, err := sealCipher(passphrase, v.salt, v.iterations)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	v.data = aead.Seal(nonce, nonce, []byte(answer), nil)
+	return v.String(), nil
+}
+
+func (v *sealedValue) newSalt() error {
+	v.salt = make([]byte, sealSaltSize)
+	if _, err := rand.Read(v.salt); err != nil {
+		return fmt.Errorf("couldn't make a salt: %w", err)
+	}
+	return nil
+}
+
+// sealCipher returns AES-256-GCM keyed with the passphrase.
+func sealCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
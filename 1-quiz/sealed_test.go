@@ -0,0 +1,160 @@
+package quiz
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealHashed(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem Problem
+		given   string
+		want    float64
+	}{
+		{"exact", Problem{Answer: "Paris", Matcher: Exact{}}, "Paris", 1},
+		{"exact is case sensitive", Problem{Answer: "Paris", Matcher: Exact{}}, "paris", 0},
+		{"nocase", Problem{Answer: "Paris"}, "pARIS", 1},
+		{"nocase folds unicode", Problem{Answer: "Ärger"}, "äRGER", 1},
+		{"nocase folds greek", Problem{Answer: "Σίσυφος"}, "σίσυφοσ", 1},
+		{"wrong", Problem{Answer: "Paris"}, "Lyon", 0},
+		{"alternative", Problem{Answer: "colour", Alternatives: []string{"color"}}, "COLOR", 1},
+		{"normalized", Problem{Answer: "café"}, "cafe\u0301", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.problem.Question = "q"
+			sealed, err := Seal(tt.problem, SealOptions{Normalization: NFC})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sealed.IsEncrypted() || !sealed.IsSealed() {
+				t.Fatalf("the answer wasn't hashed: %q", sealed.Answer)
+			}
+			q := New([]Problem{sealed}, WithNormalization(NFC))
+			if got := q.grade(sealed, tt.given, nil); got != tt.want {
+				t.Errorf("grade(%q) = %v, want %v", tt.given, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSealEncrypted(t *testing.T) {
+	const passphrase = "correct horse battery staple"
+	tests := []struct {
+		name    string
+		problem Problem
+		order   []int
+		given   string
+		want    float64
+	}{
+		{"numeric", Problem{Answer: "3.14", Matcher: Numeric{Tolerance: 0.01}}, nil, "3.141", 1},
+		{"numeric wrong", Problem{Answer: "3.14", Matcher: Numeric{Tolerance: 0.01}}, nil, "3.2", 0},
+		{"regex", Problem{Answer: "colou?r", Matcher: Regex{}}, nil, "color", 1},
+		{"multiple choice", Problem{Options: []string{"4", "5", "7"}, Answer: "B,C"}, []int{0, 1, 2}, "b, c", 1},
+		{"shuffled options", Problem{Options: []string{"4", "5", "7"}, Answer: "B,C"}, []int{2, 0, 1}, "A,C", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.problem.Question = "q"
+			sealed, err := Seal(tt.problem, SealOptions{Passphrase: passphrase})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sealed.IsEncrypted() {
+				t.Fatalf("the answer wasn't encrypted: %q", sealed.Answer)
+			}
+			problems := []Problem{sealed}
+			if err := CheckPassphrase(problems, passphrase); err != nil {
+				t.Fatalf("CheckPassphrase() error = %v", err)
+			}
+			q := New(problems, WithPassphrase(passphrase))
+			if got := q.grade(sealed, tt.given, tt.order); got != tt.want {
+				t.Errorf("grade(%q) = %v, want %v", tt.given, got, tt.want)
+			}
+
+			// A wrong passphrase is caught up front, and never lets an
+			// answer through
+			if err := CheckPassphrase(problems, "wrong"); err == nil {
+				t.Error("CheckPassphrase() accepted the wrong passphrase")
+			}
+			if err := CheckPassphrase(problems, ""); !errors.Is(err, ErrNoPassphrase) {
+				t.Errorf("CheckPassphrase() without a passphrase error = %v, want %v", err, ErrNoPassphrase)
+			}
+			q = New(problems, WithPassphrase("wrong"))
+			if got := q.grade(sealed, tt.given, tt.order); got != 0 {
+				t.Errorf("grade(%q) with the wrong passphrase = %v, want 0", tt.given, got)
+			}
+		})
+	}
+}
+
+func TestSealNeedsPassphrase(t *testing.T) {
+	_, err := Seal(Problem{Question: "pi", Answer: "3.14", Matcher: Numeric{Tolerance: 0.01}}, SealOptions{})
+	if err == nil {
+		t.Error("Seal() encrypted an answer without a passphrase")
+	}
+}
+
+func TestSealedBankHidesAnswers(t *testing.T) {
+	problems := []Problem{
+		{Question: "capital of France", Answer: "Paris", Alternatives: []string{"Lutetia"}},
+		{Question: "pi", Answer: "3.14159", Matcher: Numeric{Tolerance: 0.001}},
+		{Question: "Pick the primes", Options: []string{"4", "5", "7"}, Answer: "B,C"},
+	}
+	for _, ext := range []string{".csv", ".json", ".yaml", ".toml"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sealed"+ext)
+			d, err := OpenBankDocument(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range problems {
+				sealed, err := Seal(p, SealOptions{Passphrase: "correct horse battery staple"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := d.Add(sealed); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := d.Save(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, plain := range []string{"Paris", "Lutetia", "3.14159", "B,C"} {
+				if strings.Contains(string(data), plain) {
+					t.Errorf("the sealed bank contains %q:\n%s", plain, data)
+				}
+			}
+
+			// The sealed bank loads back as sealed problems
+			loaded, err := FileSource{Path: path}.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range loaded {
+				if !p.IsSealed() {
+					t.Errorf("%q was loaded unsealed", p.Question)
+				}
+			}
+		})
+	}
+}
+
+func TestSealedRecord(t *testing.T) {
+	sealed, err := Seal(Problem{Question: "capital of France", Answer: "Paris"}, SealOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := newRecordAnswer(Answer{Problem: sealed, Given: "Paris", Correct: true, Credit: 1})
+	if rec.Expected != sealedExpected {
+		t.Errorf("Expected = %q, want %q", rec.Expected, sealedExpected)
+	}
+}
@@ -0,0 +1,195 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Filter chooses problems by their tags and difficulty. The zero Filter
+// keeps every problem.
+type Filter struct {
+	// Tags keeps problems with any of the tags, or every problem when
+	// empty
+	Tags []string
+	// ExcludeTags drops problems with any of the tags
+	ExcludeTags []string
+	// MinDifficulty and MaxDifficulty keep problems within the range,
+	// where zero leaves that end open. Unrated problems are dropped when
+	// either is set.
+	MinDifficulty int
+	MaxDifficulty int
+}
+
+// Match reports whether the filter keeps the problem.
+func (f Filter) Match(p Problem) bool {
+	if len(f.Tags) > 0 && !hasAnyTag(p, f.Tags) {
+		return false
+	}
+	if hasAnyTag(p, f.ExcludeTags) {
+		return false
+	}
+	if f.MinDifficulty > 0 || f.MaxDifficulty > 0 {
+		if p.Difficulty == 0 || p.Difficulty < f.MinDifficulty {
+			return false
+		}
+		if f.MaxDifficulty > 0 && p.Difficulty > f.MaxDifficulty {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the problems the filter keeps, in the same order.
+func (f Filter) Apply(problems []Problem) []Problem {
+	var kept []Problem
+	for _, p := range problems {
+		if f.Match(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// hasTag reports whether the problem has the tag, ignoring case.
+func hasTag(p Problem, tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(p Problem, tags []string) bool {
+	for _, tag := range tags {
+		if hasTag(p, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags splits a comma separated list of tags, e.g. "maths,science".
+func ParseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// ParseDifficulty parses a difficulty level or range into its bounds:
+// "2" is exactly 2, "1-3" is from 1 to 3, "2-" is 2 and above and "-3" is
+// up to 3. An empty string has no bounds.
+func ParseDifficulty(s string) (min, max int, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, nil
+	}
+
+	lo, hi, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hi = lo
+	}
+	if lo != "" {
+		if min, err = strconv.Atoi(strings.TrimSpace(lo)); err != nil || min < 1 {
+			return 0, 0, fmt.Errorf("invalid difficulty %q", s)
+		}
+	}
+	if hi != "" {
+		if max, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil || max < 1 {
+			return 0, 0, fmt.Errorf("invalid difficulty %q", s)
+		}
+	}
+	if min == 0 && max == 0 {
+		return 0, 0, fmt.Errorf("invalid difficulty %q", s)
+	}
+	if max > 0 && min > max {
+		return 0, 0, fmt.Errorf("invalid difficulty %q: %d is above %d", s, min, max)
+	}
+	return min, max, nil
+}
+
+// Sample picks n problems, drawing evenly across the given tags so that
+// each is asked about as often as the others. Without any tags it draws
+// evenly across every tag in the problems, with untagged problems counted
+// together. A tag which runs out of problems leaves its share to the rest.
+// The picked problems keep their order, and all of them are returned when
+// there are no more than n.
+func Sample(problems []Problem, n int, tags []string, rnd *rand.Rand) []Problem {
+	if n <= 0 || n >= len(problems) {
+		return problems
+	}
+	if len(tags) == 0 {
+		tags = allTags(problems)
+	}
+
+	// Each tag draws from its own shuffled queue of problems. Problems
+	// with several tags are in several queues but are only picked once.
+	order := rnd.Perm(len(problems))
+	queues := make([][]int, len(tags))
+	for _, i := range order {
+		for t, tag := range tags {
+			if (tag == "" && len(problems[i].Tags) == 0) || (tag != "" && hasTag(problems[i], tag)) {
+				queues[t] = append(queues[t], i)
+			}
+		}
+	}
+
+	picked := make([]bool, len(problems))
+	count := 0
+	for start := rnd.Intn(len(tags)); count < n; {
+		progress := false
+		for k := 0; k < len(tags) && count < n; k++ {
+			t := (start + k) % len(tags)
+			for len(queues[t]) > 0 {
+				i := queues[t][0]
+				queues[t] = queues[t][1:]
+				if !picked[i] {
+					picked[i] = true
+					count++
+					progress = true
+					break
+				}
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+
+	sample := make([]Problem, 0, count)
+	for i, p := range problems {
+		if picked[i] {
+			sample = append(sample, p)
+		}
+	}
+	return sample
+}
+
+// allTags returns every tag used by the problems in sorted order, with ""
+// standing for untagged problems.
+func allTags(problems []Problem) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, p := range problems {
+		if len(p.Tags) == 0 && !seen[""] {
+			seen[""] = true
+			tags = append(tags, "")
+		}
+		for _, t := range p.Tags {
+			key := strings.ToLower(t)
+			if !seen[key] {
+				seen[key] = true
+				tags = append(tags, key)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
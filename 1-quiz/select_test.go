@@ -0,0 +1,158 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// taggedProblems makes count problems for each tag, where "" makes
+// untagged ones.
+func taggedProblems(counts map[string]int) []Problem {
+	var problems []Problem
+	for _, tag := range []string{"", "maths", "science", "history"} {
+		for i := 0; i < counts[tag]; i++ {
+			p := Problem{Question: fmt.Sprintf("%s %d", tag, i), Answer: "x"}
+			if tag != "" {
+				p.Tags = []string{tag}
+			}
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[string]int
+		n      int
+		tags   []string
+		// want is how many problems with each tag are picked
+		want map[string]int
+	}{
+		{
+			name:   "even",
+			counts: map[string]int{"maths": 10, "science": 10, "history": 10},
+			n:      9,
+			want:   map[string]int{"maths": 3, "science": 3, "history": 3},
+		},
+		{
+			name:   "untagged problems are a stratum of their own",
+			counts: map[string]int{"": 10, "maths": 10},
+			n:      4,
+			want:   map[string]int{"": 2, "maths": 2},
+		},
+		{
+			name:   "a small tag leaves its share to the rest",
+			counts: map[string]int{"maths": 1, "science": 10, "history": 10},
+			n:      9,
+			want:   map[string]int{"maths": 1, "science": 4, "history": 4},
+		},
+		{
+			name:   "given tags",
+			counts: map[string]int{"maths": 10, "science": 10, "history": 10},
+			n:      6,
+			tags:   []string{"Maths", "history"},
+			want:   map[string]int{"maths": 3, "history": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := taggedProblems(tt.counts)
+			for seed := int64(1); seed <= 20; seed++ {
+				sample := Sample(problems, tt.n, tt.tags, rand.New(rand.NewSource(seed)))
+				if len(sample) != tt.n {
+					t.Fatalf("seed %d: Sample() = %d problems, want %d", seed, len(sample), tt.n)
+				}
+				got := make(map[string]int)
+				seen := make(map[string]bool)
+				for _, p := range sample {
+					if seen[p.Question] {
+						t.Fatalf("seed %d: %q was picked twice", seed, p.Question)
+					}
+					seen[p.Question] = true
+					tag := ""
+					if len(p.Tags) > 0 {
+						tag = p.Tags[0]
+					}
+					got[tag]++
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("seed %d: Sample() picked %v, want %v", seed, got, tt.want)
+				}
+				if !inBankOrder(problems, sample) {
+					t.Fatalf("seed %d: Sample() changed the order of the problems", seed)
+				}
+			}
+		})
+	}
+}
+
+func TestSampleAll(t *testing.T) {
+	problems := taggedProblems(map[string]int{"maths": 2, "science": 3})
+	for _, n := range []int{0, len(problems), len(problems) + 1, 100} {
+		if got := Sample(problems, n, nil, rand.New(rand.NewSource(1))); !reflect.DeepEqual(got, problems) {
+			t.Errorf("Sample(%d) of %d problems = %v, want all of them", n, len(problems), got)
+		}
+	}
+
+	// Too few problems with the given tags for n asks every one of them
+	got := Sample(problems, 4, []string{"maths"}, rand.New(rand.NewSource(1)))
+	if len(got) != 2 || !hasTag(got[0], "maths") || !hasTag(got[1], "maths") {
+		t.Errorf("Sample() = %v, want both maths problems", got)
+	}
+}
+
+func TestSampleSeeded(t *testing.T) {
+	problems := taggedProblems(map[string]int{"maths": 10, "science": 10})
+	first := Sample(problems, 5, nil, rand.New(rand.NewSource(7)))
+	second := Sample(problems, 5, nil, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed sampled %v and then %v", first, second)
+	}
+}
+
+// inBankOrder reports whether the sample is in the order of the bank.
+func inBankOrder(bank, sample []Problem) bool {
+	j := 0
+	for _, p := range bank {
+		if j < len(sample) && sample[j].Question == p.Question {
+			j++
+		}
+	}
+	return j == len(sample)
+}
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		s        string
+		min, max int
+		err      bool
+	}{
+		{"", 0, 0, false},
+		{"2", 2, 2, false},
+		{" 1-3 ", 1, 3, false},
+		{"1 - 3", 1, 3, false},
+		{"2-", 2, 0, false},
+		{"-3", 0, 3, false},
+		{"3-3", 3, 3, false},
+		{"3-1", 0, 0, true},
+		{"0", 0, 0, true},
+		{"-", 0, 0, true},
+		{"hard", 0, 0, true},
+		{"1-x", 0, 0, true},
+		{"-1", 0, 1, false},
+	}
+	for _, tt := range tests {
+		min, max, err := ParseDifficulty(tt.s)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseDifficulty(%q) error = %v, want error %v", tt.s, err, tt.err)
+			continue
+		}
+		if min != tt.min || max != tt.max {
+			t.Errorf("ParseDifficulty(%q) = %d, %d, want %d, %d", tt.s, min, max, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package quiz
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned by a SessionStore for unknown session
+// IDs.
+var ErrSessionNotFound = errors.New("quiz session not found")
+
+// SessionStore keeps the sessions being taken through the web handler.
+type SessionStore interface {
+	// Create stores a new session and returns its ID
+	Create(s *Session) (string, error)
+	Get(id string) (*Session, error)
+	Delete(id string) error
+}
+
+const (
+	// DefaultSessionTTL is how long a MemoryStore keeps a session which
+	// nobody has asked for
+	DefaultSessionTTL = time.Hour
+
+	// maxRequestBody is the largest request body the handler reads
+	maxRequestBody = 4096
+)
+
+// MemoryStoreOption configures a MemoryStore.
+type MemoryStoreOption func(m *MemoryStore)
+
+// WithSessionTTL sets how long sessions are kept after they were last
+// used.
+func WithSessionTTL(d time.Duration) MemoryStoreOption {
+	return func(m *MemoryStore) {
+		m.ttl = d
+	}
+}
+
+// WithStoreClock replaces the clock used to expire sessions.
+func WithStoreClock(c Clock) MemoryStoreOption {
+	return func(m *MemoryStore) {
+		m.clock = c
+	}
+}
+
+// MemoryStore is a SessionStore which keeps sessions in memory. Sessions
+// which haven't been used for a while, such as ones which were finished or
+// left half way, are thrown away.
+type MemoryStore struct {
+	ttl   time.Duration
+	clock Clock
+
+	mu       sync.Mutex
+	sessions map[string]*storedSession
+}
+
+type storedSession struct {
+	session  *Session
+	lastUsed time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	m := MemoryStore{
+		ttl:      DefaultSessionTTL,
+		clock:    RealClock{},
+		sessions: make(map[string]*storedSession),
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return &m
+}
+
+func (m *MemoryStore) Create(s *Session) (string, error) {
+	id, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	m.evict(now)
+	m.sessions[id] = &storedSession{session: s, lastUsed: now}
+	return id, nil
+}
+
+func (m *MemoryStore) Get(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	stored, ok := m.sessions[id]
+	if !ok || m.expired(stored, now) {
+		delete(m.sessions, id)
+		return nil, ErrSessionNotFound
+	}
+	stored.lastUsed = now
+	return stored.session, nil
+}
+
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, id)
+	return nil
+}
+
+// evict throws away the sessions which have expired. Sessions are only
+// swept when new ones are created, so the store never grows by more than
+// the sessions started within the TTL.
+func (m *MemoryStore) evict(now time.Time) {
+	for id, stored := range m.sessions {
+		if m.expired(stored, now) {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+func (m *MemoryStore) expired(stored *storedSession, now time.Time) bool {
+	return m.ttl > 0 && now.Sub(stored.lastUsed) >= m.ttl
+}
+
+// randomToken returns a random ID which is hard to guess, so that people
+// can't answer each other's sessions.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HandlerOption configures the handler returned by NewHandler.
+type HandlerOption func(h *handler)
+
+// WithSessionStore replaces the in-memory session store.
+func WithSessionStore(s SessionStore) HandlerOption {
+	return func(h *handler) {
+		h.store = s
+	}
+}
+
+// WithPageTemplate replaces the HTML page served at "/".
+func WithPageTemplate(t *template.Template) HandlerOption {
+	return func(h *handler) {
+		h.page = t
+	}
+}
+
+// NewHandler returns an http.Handler which serves the quiz as a web page
+// backed by a JSON API. newQuiz is called for every new session, so it can
+// shuffle the problems differently each time.
+//
+// The API is:
+//
+//	POST   /api/sessions               start a session
+//	GET    /api/sessions/{id}/question get the current question
+//	POST   /api/sessions/{id}/answer   answer it with {"number": 1, "answer": "10", "hints": 0}
+//	GET    /api/sessions/{id}/result   get the result so far
+//	DELETE /api/sessions/{id}          throw the session away
+func NewHandler(newQuiz func() *Quiz, opts ...HandlerOption) http.Handler {
+	h := handler{
+		newQuiz: newQuiz,
+		store:   NewMemoryStore(),
+		page:    pageTpl,
+	}
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.servePage)
+	mux.HandleFunc("/api/sessions", h.startSession)
+	mux.HandleFunc("/api/sessions/", h.serveSession)
+	return mux
+}
+
+type handler struct {
+	newQuiz func() *Quiz
+	store   SessionStore
+	page    *template.Template
+}
+
+func (h handler) servePage(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(rw, r)
+		return
+	}
+	if err := h.page.Execute(rw, nil); err != nil {
+		log.Printf("%v", err)
+		http.Error(rw, "Something went wrong...", http.StatusInternalServerError)
+	}
+}
+
+type sessionResponse struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+	// TimeLimit is the time limit for the whole quiz in seconds, or 0
+	TimeLimit float64 `json:"time_limit"`
+}
+
+func (h handler) startSession(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(rw, http.MethodPost)
+		return
+	}
+
+	q := h.newQuiz()
+	s := q.Start()
+	id, err := h.store.Create(s)
+	if err != nil {
+		log.Printf("%v", err)
+		writeError(rw, http.StatusInternalServerError, "couldn't start the quiz")
+		return
+	}
+	writeJSON(rw, http.StatusCreated, sessionResponse{
+		ID:        id,
+		Total:     q.strategy.Len(),
+		TimeLimit: q.timeLimit.Seconds(),
+	})
+}
+
+// serveSession routes requests for "/api/sessions/{id}[/action]".
+func (h handler) serveSession(rw http.ResponseWriter, r *http.Request) {
+	// "/api/sessions/abc/answer" => ["abc", "answer"]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/sessions/"), "/")
+	id, action := parts[0], ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	if len(parts) > 2 {
+		http.NotFound(rw, r)
+		return
+	}
+
+	if action == "" && r.Method == http.MethodDelete {
+		if err := h.store.Delete(id); err != nil {
+			h.storeError(rw, err)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s, err := h.store.Get(id)
+	if err != nil {
+		h.storeError(rw, err)
+		return
+	}
+
+	switch action {
+	case "question":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(rw, http.MethodGet)
+			return
+		}
+		h.question(rw, s)
+	case "answer":
+		if r.Method != http.MethodPost {
+			methodNotAllowed(rw, http.MethodPost)
+			return
+		}
+		h.answer(rw, r, s)
+	case "result":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(rw, http.MethodGet)
+			return
+		}
+		writeJSON(rw, http.StatusOK, newResultResponse(s.Result()))
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+type questionResponse struct {
+	Done        bool     `json:"done"`
+	Number      int      `json:"number,omitempty"`
+	Total       int      `json:"total,omitempty"`
+	Question    string   `json:"question,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	MultiSelect bool     `json:"multi_select,omitempty"`
+	Hints       []string `json:"hints,omitempty"`
+	// TimeLeft and ProblemTimeLeft are in seconds and left out when there
+	// is no limit
+	TimeLeft        *float64 `json:"time_left,omitempty"`
+	ProblemTimeLeft *float64 `json:"problem_time_left,omitempty"`
+}
+
+func (h handler) question(rw http.ResponseWriter, s *Session) {
+	q, ok := s.Next()
+	if !ok {
+		writeJSON(rw, http.StatusOK, questionResponse{Done: true})
+		return
+	}
+
+	now := s.quiz.clock.Now()
+	writeJSON(rw, http.StatusOK, questionResponse{
+		Number:          q.Number,
+		Total:           q.Total,
+		Question:        q.Question,
+		Options:         q.Options,
+		MultiSelect:     q.MultiSelect,
+		Hints:           q.Hints,
+		TimeLeft:        secondsUntil(now, q.Deadline),
+		ProblemTimeLeft: secondsUntil(now, q.ProblemDeadline),
+	})
+}
+
+func secondsUntil(now, t time.Time) *float64 {
+	if t.IsZero() {
+		return nil
+	}
+	secs := t.Sub(now).Seconds()
+	if secs < 0 {
+		secs = 0
+	}
+	return &secs
+}
+
+type answerRequest struct {
+	Number int    `json:"number"`
+	Answer string `json:"answer"`
+	// Hints is how many of the problem's hints were revealed
+	Hints int `json:"hints"`
+}
+
+type answerResponse struct {
+	Correct bool    `json:"correct"`
+	Credit  float64 `json:"credit"`
+	Done    bool    `json:"done"`
+}
+
+func (h handler) answer(rw http.ResponseWriter, r *http.Request, s *Session) {
+	var req answerRequest
+	body := http.MaxBytesReader(rw, r.Body, maxRequestBody)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		writeError(rw, http.StatusBadRequest, "invalid answer: "+err.Error())
+		return
+	}
+
+	a, err := s.Submit(req.Number, req.Answer, req.Hints)
+	switch {
+	case errors.Is(err, ErrSessionDone):
+		writeError(rw, http.StatusGone, err.Error())
+		return
+	case errors.Is(err, ErrWrongProblem):
+		writeError(rw, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("%v", err)
+		writeError(rw, http.StatusInternalServerError, "couldn't check the answer")
+		return
+	}
+	writeJSON(rw, http.StatusOK, answerResponse{
+		Correct: a.Correct,
+		Credit:  a.Credit,
+		Done:    s.Done(),
+	})
+}
+
+type resultResponse struct {
+	Score     int            `json:"score"`
+	Points    float64        `json:"points"`
+	MaxPoints float64        `json:"max_points"`
+	Total     int            `json:"total"`
+	TimedOut  bool           `json:"timed_out"`
+	Answers   []resultAnswer `json:"answers"`
+}
+
+type resultAnswer struct {
+	Question string  `json:"question"`
+	Given    string  `json:"given"`
+	Correct  bool    `json:"correct"`
+	Credit   float64 `json:"credit"`
+	Points   float64 `json:"points"`
+	// Latency is in seconds
+	Latency  float64 `json:"latency"`
+	TimedOut bool    `json:"timed_out"`
+}
+
+func newResultResponse(result Result) resultResponse {
+	resp := resultResponse{
+		Score:     result.Score(),
+		Points:    result.Points(),
+		MaxPoints: result.MaxPoints,
+		Total:     result.Total,
+		TimedOut:  result.TimedOut,
+		Answers:   []resultAnswer{},
+	}
+	for _, a := range result.Answers {
+		resp.Answers = append(resp.Answers, resultAnswer{
+			Question: a.Problem.Question,
+			Given:    a.Given,
+			Correct:  a.Correct,
+			Credit:   a.Credit,
+			Points:   a.Points,
+			Latency:  a.Latency.Seconds(),
+			TimedOut: a.TimedOut,
+		})
+	}
+	return resp
+}
+
+func (h handler) storeError(rw http.ResponseWriter, err error) {
+	if errors.Is(err, ErrSessionNotFound) {
+		writeError(rw, http.StatusNotFound, err.Error())
+		return
+	}
+	log.Printf("%v", err)
+	writeError(rw, http.StatusInternalServerError, "couldn't load the quiz session")
+}
+
+func methodNotAllowed(rw http.ResponseWriter, allowed string) {
+	rw.Header().Set("Allow", allowed)
+	writeError(rw, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func writeError(rw http.ResponseWriter, status int, msg string) {
+	writeJSON(rw, status, map[string]string{"error": msg})
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Printf("%v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package quiz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreExpires(t *testing.T) {
+	clock := newFakeClock()
+	m := NewMemoryStore(WithSessionTTL(time.Hour), WithStoreClock(clock))
+	q := New(testProblems)
+
+	used, err := m.Create(q.Start())
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, err := m.Create(q.Start())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Using a session keeps it alive
+	clock.Advance(45 * time.Minute)
+	if _, err := m.Get(used); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	clock.Advance(30 * time.Minute)
+	if _, err := m.Get(used); err != nil {
+		t.Errorf("Get() of a session used within the TTL: %v", err)
+	}
+	if _, err := m.Get(idle); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Get() of an idle session error = %v, want %v", err, ErrSessionNotFound)
+	}
+
+	// Sessions nobody asks for again are swept when new ones are created
+	clock.Advance(2 * time.Hour)
+	if _, err := m.Create(q.Start()); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.sessions) != 1 {
+		t.Errorf("%d sessions are kept, want 1", len(m.sessions))
+	}
+}
+
+func TestHandlerAnswerBody(t *testing.T) {
+	m := NewMemoryStore()
+	s := New(testProblems).Start()
+	s.Next()
+	id, err := m.Create(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(func() *Quiz { return New(testProblems) }, WithSessionStore(m))
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"answer", `{"number": 1, "answer": "10"}`, http.StatusOK},
+		{"too big", `{"number": 2, "answer": "` + strings.Repeat("x", maxRequestBody) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/sessions/"+id+"/answer", strings.NewReader(tt.body))
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, r)
+			if rw.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rw.Code, tt.status, rw.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerAnswerHints(t *testing.T) {
+	problems := []Problem{{Question: "capital of France", Answer: "Paris", Hints: []string{"in Europe", "on the Seine"}}}
+	tests := []struct {
+		body   string
+		hints  int
+		points float64
+	}{
+		{`{"number": 1, "answer": "Paris"}`, 0, 1},
+		{`{"number": 1, "answer": "Paris", "hints": 1}`, 1, 0.75},
+		// Only the problem's hints can have been revealed
+		{`{"number": 1, "answer": "Paris", "hints": 5}`, 2, 0.5},
+		{`{"number": 1, "answer": "Paris", "hints": -1}`, 0, 1},
+	}
+	for _, tt := range tests {
+		m := NewMemoryStore()
+		s := New(problems).Start()
+		s.Next()
+		id, err := m.Create(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := NewHandler(func() *Quiz { return New(problems) }, WithSessionStore(m))
+
+		r := httptest.NewRequest(http.MethodPost, "/api/sessions/"+id+"/answer", strings.NewReader(tt.body))
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+		if rw.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", tt.body, rw.Code, rw.Body)
+		}
+		a := s.Result().Answers[0]
+		if a.HintsUsed != tt.hints || a.Points != tt.points {
+			t.Errorf("%s: %d hints for %v points, want %d for %v", tt.body, a.HintsUsed, a.Points, tt.hints, tt.points)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package quiz
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSessionDone is returned when answering a session which has no
+	// problems left, or which has run out of time.
+	ErrSessionDone = errors.New("quiz session is over")
+	// ErrWrongProblem is returned when an answer is for a problem other
+	// than the one currently being asked, usually because the problem
+	// timed out before the answer arrived.
+	ErrWrongProblem = errors.New("answer is not for the current problem")
+)
+
+// Session is a quiz which is taken one step at a time, for when the
+// problems can't be asked in a single blocking loop like Run, such as over
+// HTTP. Time limits are enforced against the quiz clock whenever the
+// session is used. A Session is safe for concurrent use.
+type Session struct {
+	mu      sync.Mutex
+	quiz    *Quiz
+	started time.Time
+	// problem is the current problem, which the quiz's Strategy chose
+	// after the answers so far. asked is when it was shown, or zero if it
+	// hasn't been yet.
+	problem Problem
+	asked   time.Time
+	order   []int
+	result  Result
+	done    bool
+}
+
+// Question is a problem as it is shown to the user during a session.
+type Question struct {
+	// Number counts from 1
+	Number   int
+	Total    int
+	Question string
+	// Options are the options of a multiple choice problem in the order
+	// they are shown, lettered from A
+	Options     []string
+	MultiSelect bool
+	// Hints are the problem's hints, for the user to reveal one at a time.
+	// Submit is told how many were revealed.
+	Hints []string
+	// Deadline is when the whole quiz ends and ProblemDeadline is when
+	// this problem times out. Either is zero when there is no limit.
+	Deadline        time.Time
+	ProblemDeadline time.Time
+}
+
+// Start begins a session of the quiz. The time limit starts straight
+// away. The problems are asked in the order the quiz's Strategy picks
+// them, like Run, so a Quiz should only be used for one session or run at
+// a time.
+func (q *Quiz) Start() *Session {
+	s := &Session{
+		quiz:    q,
+		started: q.clock.Now(),
+		result:  Result{Total: q.strategy.Len(), MaxPoints: q.maxPoints(Result{})},
+	}
+	s.pick()
+	return s
+}
+
+// Deadline returns when the session runs out of time, or zero if there is
+// no time limit.
+func (s *Session) Deadline() time.Time {
+	if s.quiz.timeLimit <= 0 {
+		return time.Time{}
+	}
+	return s.started.Add(s.quiz.timeLimit)
+}
+
+// Next returns the problem which is currently being asked, starting its
+// timer the first time it is returned. It returns false when the session
+// is over.
+func (s *Session) Next() (Question, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	if s.done {
+		return Question{}, false
+	}
+
+	problem := s.problem
+	if s.asked.IsZero() {
+		s.asked = s.quiz.clock.Now()
+		if problem.IsMultipleChoice() {
+			s.order = optionOrder(problem, s.quiz.optionRand)
+		}
+	}
+
+	question := Question{
+		Number:      len(s.result.Answers) + 1,
+		Total:       s.result.Total,
+		Question:    problem.Question,
+		MultiSelect: problem.IsMultiSelect(),
+		Hints:       problem.Hints,
+		Deadline:    s.Deadline(),
+	}
+	for _, o := range s.order {
+		question.Options = append(question.Options, problem.Options[o])
+	}
+	if limit := s.quiz.problemLimit(problem); limit > 0 {
+		question.ProblemDeadline = s.asked.Add(limit)
+	}
+	return question, true
+}
+
+// Submit answers the problem with the given number after the given number
+// of its hints were revealed. The number has to match the problem returned
+// by Next, so that an answer which arrives after its problem timed out
+// isn't used for the next one.
+func (s *Session) Submit(number int, given string, hints int) (Answer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	if s.done {
+		return Answer{}, ErrSessionDone
+	}
+	if number != len(s.result.Answers)+1 || s.asked.IsZero() {
+		return Answer{}, ErrWrongProblem
+	}
+
+	problem := s.problem
+	// Only the hints the problem has can have been revealed
+	if hints < 0 {
+		hints = 0
+	}
+	if hints > len(problem.Hints) {
+		hints = len(problem.Hints)
+	}
+	given = strings.TrimSpace(given)
+	credit := s.quiz.grade(problem, given, s.order)
+	answer := Answer{
+		Problem:   problem,
+		Order:     s.order,
+		Given:     given,
+		Correct:   credit == 1,
+		Credit:    credit,
+		HintsUsed: hints,
+		Points:    s.quiz.score(problem, credit, hints),
+		Latency:   s.quiz.clock.Now().Sub(s.asked),
+	}
+	s.result.Answers = append(s.result.Answers, answer)
+	s.advance()
+	return answer, nil
+}
+
+// Done reports whether the session is over.
+func (s *Session) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	return s.done
+}
+
+// Result returns the answers so far. Once the session is done it is the
+// final result.
+func (s *Session) Result() Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	result := s.result
+	result.Answers = append([]Answer(nil), s.result.Answers...)
+	return result
+}
+
+// advance moves on to the next problem once the current one has been
+// answered or has timed out.
+func (s *Session) advance() {
+	s.asked = time.Time{}
+	s.order = nil
+	s.pick()
+}
+
+// pick asks the strategy for the next problem, ending the session once
+// there are none left.
+func (s *Session) pick() {
+	problem, ok := s.quiz.strategy.Next(s.result.Answers)
+	if !ok {
+		s.done = true
+		return
+	}
+	s.problem = problem
+}
+
+// expire applies the time limits, ending the session once the quiz is out
+// of time and skipping the current problem once it is.
+func (s *Session) expire() {
+	if s.done {
+		return
+	}
+	now := s.quiz.clock.Now()
+
+	if deadline := s.Deadline(); !deadline.IsZero() && !now.Before(deadline) {
+		if !s.asked.IsZero() {
+			s.result.Answers = append(s.result.Answers, Answer{
+				Problem:  s.problem,
+				Order:    s.order,
+				Latency:  deadline.Sub(s.asked),
+				TimedOut: true,
+			})
+		}
+		s.result.TimedOut = true
+		s.result.Ended = EndTimeUp
+		s.done = true
+		return
+	}
+
+	if s.asked.IsZero() {
+		return
+	}
+	problem := s.problem
+	if limit := s.quiz.problemLimit(problem); limit > 0 && now.Sub(s.asked) >= limit {
+		s.result.Answers = append(s.result.Answers, Answer{
+			Problem:  problem,
+			Order:    s.order,
+			Latency:  limit,
+			TimedOut: true,
+		})
+		s.advance()
+	}
+}
@@ -0,0 +1,277 @@
+package quiz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source is somewhere a problem bank can be loaded from.
+type Source interface {
+	// Load reads every problem from the source, with Problem.Source set
+	// to where each one came from.
+	Load() ([]Problem, error)
+	// String describes the source.
+	String() string
+}
+
+// SourceFor returns the source for a bank given on the command line: "-"
+// for stdin, an http or https URL, a glob pattern such as "banks/*.yaml",
+// a directory or a single file.
+func SourceFor(spec string) Source {
+	switch {
+	case spec == "-":
+		return ReaderSource{Name: "stdin", R: os.Stdin}
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return URLSource{URL: spec}
+	case strings.ContainsAny(spec, "*?["):
+		return GlobSource{Pattern: spec}
+	}
+	if info, err := os.Stat(spec); err == nil && info.IsDir() {
+		return DirSource{Dir: spec}
+	}
+	return FileSource{Path: spec}
+}
+
+// FileSource loads a single bank file with the loader for its extension.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Load() ([]Problem, error) {
+	problems, err := LoadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return withSource(problems, s.Path), nil
+}
+
+func (s FileSource) String() string {
+	return s.Path
+}
+
+// DirSource loads every bank file in a directory and the directories
+// inside it, in order of their paths. Files without a registered loader
+// are skipped, as are hidden files and directories.
+type DirSource struct {
+	Dir string
+}
+
+func (s DirSource) Load() ([]Problem, error) {
+	var files []string
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != s.Dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, err := LoaderFor(path); err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no problem banks in the directory", s.Dir)
+	}
+	return loadFiles(files)
+}
+
+func (s DirSource) String() string {
+	return s.Dir
+}
+
+// GlobSource loads every file matching a pattern, as understood by
+// filepath.Match, in order of their paths.
+type GlobSource struct {
+	Pattern string
+}
+
+func (s GlobSource) Load() ([]Problem, error) {
+	files, err := filepath.Glob(s.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Pattern, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: no files match", s.Pattern)
+	}
+	return loadFiles(files)
+}
+
+func (s GlobSource) String() string {
+	return s.Pattern
+}
+
+// loadFiles merges the banks in files. IDs have to be unique across all of
+// them, so that problems can still be told apart.
+func loadFiles(files []string) ([]Problem, error) {
+	var problems []Problem
+	seen := make(map[string]string)
+	for _, file := range files {
+		loaded, err := FileSource{Path: file}.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range loaded {
+			if p.ID == "" {
+				continue
+			}
+			if first, ok := seen[p.ID]; ok && first != file {
+				return nil, fmt.Errorf("%s: duplicate id %q, first used in %s", file, p.ID, first)
+			}
+			seen[p.ID] = file
+		}
+		problems = append(problems, loaded...)
+	}
+	return problems, nil
+}
+
+// ReaderSource loads a bank from a reader, such as stdin. The format is
+// taken from the extension of Name when it has one, and is otherwise
+// worked out from the bank itself.
+type ReaderSource struct {
+	Name string
+	R    io.Reader
+}
+
+func (s ReaderSource) Load() ([]Problem, error) {
+	data, err := io.ReadAll(s.R)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name, err)
+	}
+	return loadData(s.Name, filepath.Ext(s.Name), data)
+}
+
+func (s ReaderSource) String() string {
+	return s.Name
+}
+
+// maxDownload is the largest bank URLSource will download
+const maxDownload = 10 << 20
+
+// defaultDownloadTimeout is how long URLSource waits for a bank without a
+// Client of its own
+const defaultDownloadTimeout = 30 * time.Second
+
+// URLSource downloads a bank over http or https. The format is taken from
+// the extension of the URL's path, then the Content-Type of the response,
+// and is otherwise worked out from the bank itself.
+type URLSource struct {
+	URL string
+	// Client is used to download the bank, or a client with a 30 second
+	// timeout when nil
+	Client *http.Client
+}
+
+func (s URLSource) Load() ([]Problem, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultDownloadTimeout}
+	}
+	resp, err := client.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", s.URL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.URL, err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("%s: the bank is bigger than %d MB", s.URL, maxDownload>>20)
+	}
+
+	ext := ""
+	if u, err := url.Parse(s.URL); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	if _, ok := loaders[strings.ToLower(ext)]; !ok {
+		ext = contentTypeExt(resp.Header.Get("Content-Type"))
+	}
+	return loadData(s.URL, ext, data)
+}
+
+func (s URLSource) String() string {
+	return s.URL
+}
+
+// contentTypeExt returns the bank file extension for a media type, or ""
+// when it isn't one of the bank formats.
+func contentTypeExt(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv":
+		return ".csv"
+	case "application/json":
+		return ".json"
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return ".yaml"
+	case "application/toml":
+		return ".toml"
+	}
+	return ""
+}
+
+// loadData reads a bank with the loader for ext, or guesses the format
+// when there is no loader for it.
+func loadData(name, ext string, data []byte) ([]Problem, error) {
+	l, ok := loaders[strings.ToLower(ext)]
+	if !ok {
+		l = loaders[detectFormat(data)]
+	}
+	problems, err := l(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return withSource(problems, name), nil
+}
+
+// detectFormat guesses the extension for a bank from its contents, falling
+// back to csv.
+func detectFormat(data []byte) string {
+	// Comments can come before the start of a YAML or TOML bank
+	text := strings.TrimSpace(string(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))))
+	for strings.HasPrefix(text, "#") {
+		_, rest, _ := strings.Cut(text, "\n")
+		text = strings.TrimSpace(rest)
+	}
+	switch {
+	case strings.HasPrefix(text, "{"):
+		return ".json"
+	case strings.HasPrefix(text, "[[problems]]"):
+		return ".toml"
+	case strings.HasPrefix(text, "problems:"), strings.HasPrefix(text, "---"):
+		return ".yaml"
+	}
+	return ".csv"
+}
+
+// withSource records where the problems were loaded from.
+func withSource(problems []Problem, source string) []Problem {
+	for i := range problems {
+		problems[i].Source = source
+	}
+	return problems
+}
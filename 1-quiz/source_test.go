@@ -0,0 +1,88 @@
+package quiz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestURLSource(t *testing.T) {
+	const (
+		csvBank  = "5+5,10\ncapital of France,Paris\n"
+		jsonBank = `{"problems": [{"question": "5+5", "answer": "10"}, {"question": "capital of France", "answer": "Paris"}]}`
+		yamlBank = "problems:\n  - question: 5+5\n    answer: \"10\"\n  - question: capital of France\n    answer: Paris\n"
+	)
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		status      int
+		body        string
+		// err is part of the error expected, or empty when the bank
+		// should load
+		err string
+	}{
+		{name: "csv by extension", path: "/bank.csv", body: csvBank},
+		{name: "json by extension", path: "/bank.json", contentType: "text/plain", body: jsonBank},
+		{name: "yaml by content type", path: "/bank", contentType: "application/yaml; charset=utf-8", body: yamlBank},
+		{name: "json by content type", path: "/download?id=1", contentType: "application/json", body: jsonBank},
+		{name: "extension wins over content type", path: "/bank.csv", contentType: "application/json", body: csvBank},
+		{name: "unknown content type is detected", path: "/bank", contentType: "application/octet-stream", body: yamlBank},
+		{name: "extension mismatch", path: "/bank.json", body: csvBank, err: "/bank.json"},
+		{name: "content type mismatch", path: "/bank", contentType: "application/json", body: csvBank, err: "/bank"},
+		{name: "not found", path: "/bank.csv", status: http.StatusNotFound, err: "404 Not Found"},
+		{name: "server error", path: "/bank.csv", status: http.StatusInternalServerError, err: "500 Internal Server Error"},
+		{name: "too big", path: "/bank.csv", body: strings.Repeat("x", maxDownload+1), err: "bigger than 10 MB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					rw.Header().Set("Content-Type", tt.contentType)
+				}
+				if tt.status != 0 {
+					rw.WriteHeader(tt.status)
+				}
+				rw.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			s := URLSource{URL: server.URL + tt.path, Client: server.Client()}
+			problems, err := s.Load()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load() error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(problems) != 2 || problems[0].Answer != "10" || problems[1].Answer != "Paris" {
+				t.Fatalf("Load() = %+v, want the two problems", problems)
+			}
+			for _, p := range problems {
+				if p.Source != s.URL {
+					t.Errorf("Source = %q, want %q", p.Source, s.URL)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceFor(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Source
+	}{
+		{"http://example.com/bank.csv", URLSource{URL: "http://example.com/bank.csv"}},
+		{"https://example.com/bank", URLSource{URL: "https://example.com/bank"}},
+		{"banks/*.yaml", GlobSource{Pattern: "banks/*.yaml"}},
+	}
+	for _, tt := range tests {
+		if got := SourceFor(tt.spec); got != tt.want {
+			t.Errorf("SourceFor(%q) = %#v, want %#v", tt.spec, got, tt.want)
+		}
+	}
+}
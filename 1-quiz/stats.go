@@ -0,0 +1,150 @@
+package quiz
+
+import (
+	"sort"
+	"time"
+)
+
+// ProblemStats sums up every time a problem was asked across the history.
+type ProblemStats struct {
+	Bank     string
+	Key      string
+	Question string
+	Asked    int
+	Correct  int
+	Missed   int
+	// TotalLatency is the time spent on the problem over every run
+	TotalLatency time.Duration
+}
+
+// Accuracy returns the fraction of times the problem was answered
+// correctly.
+func (s ProblemStats) Accuracy() float64 {
+	if s.Asked == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Asked)
+}
+
+// AverageLatency returns the average time taken to answer the problem.
+func (s ProblemStats) AverageLatency() time.Duration {
+	if s.Asked == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Asked)
+}
+
+// Summary is the statistics for a set of quiz records.
+type Summary struct {
+	// Runs are the records the summary was built from, oldest first
+	Runs []Record
+	// Problems has an entry for every problem which was asked
+	Problems []ProblemStats
+}
+
+// Summarize works out statistics for the records.
+func Summarize(records []Record) Summary {
+	runs := append([]Record(nil), records...)
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Time.Before(runs[j].Time)
+	})
+
+	type key struct{ bank, key string }
+	index := make(map[key]int)
+	var problems []ProblemStats
+	for _, run := range runs {
+		for _, a := range run.Answers {
+			k := key{run.Bank, a.Key()}
+			i, ok := index[k]
+			if !ok {
+				i = len(problems)
+				index[k] = i
+				problems = append(problems, ProblemStats{
+					Bank:     run.Bank,
+					Key:      a.Key(),
+					Question: a.Question,
+				})
+			}
+			s := &problems[i]
+			s.Asked++
+			if a.Correct {
+				s.Correct++
+			} else {
+				s.Missed++
+			}
+			s.TotalLatency += a.Latency()
+		}
+	}
+
+	return Summary{Runs: runs, Problems: problems}
+}
+
+// MostMissed returns up to n problems which were missed the most, worst
+// first.
+func (s Summary) MostMissed(n int) []ProblemStats {
+	return s.top(n, func(a, b ProblemStats) bool {
+		if a.Missed != b.Missed {
+			return a.Missed > b.Missed
+		}
+		return a.Accuracy() < b.Accuracy()
+	}, func(p ProblemStats) bool { return p.Missed > 0 })
+}
+
+// Slowest returns up to n problems with the longest average time to
+// answer, slowest first.
+func (s Summary) Slowest(n int) []ProblemStats {
+	return s.top(n, func(a, b ProblemStats) bool {
+		return a.AverageLatency() > b.AverageLatency()
+	}, func(ProblemStats) bool { return true })
+}
+
+func (s Summary) top(n int, less func(a, b ProblemStats) bool, keep func(ProblemStats) bool) []ProblemStats {
+	var problems []ProblemStats
+	for _, p := range s.Problems {
+		if keep(p) {
+			problems = append(problems, p)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return less(problems[i], problems[j])
+	})
+	if len(problems) > n {
+		problems = problems[:n]
+	}
+	return problems
+}
+
+// Accuracy returns the overall fraction of points scored across every
+// run.
+func (s Summary) Accuracy() float64 {
+	points, max := 0.0, 0.0
+	for _, r := range s.Runs {
+		points += r.Points
+		max += r.Max()
+	}
+	if max == 0 {
+		return 0
+	}
+	return points / max
+}
+
+// Trend compares the average accuracy of the older half of the runs with
+// the newer half. It returns zeros when there are fewer than two runs.
+func (s Summary) Trend() (older, newer float64) {
+	if len(s.Runs) < 2 {
+		return 0, 0
+	}
+	half := len(s.Runs) / 2
+	return averageAccuracy(s.Runs[:half]), averageAccuracy(s.Runs[half:])
+}
+
+func averageAccuracy(runs []Record) float64 {
+	if len(runs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, r := range runs {
+		sum += r.Accuracy()
+	}
+	return sum / float64(len(runs))
+}
@@ -0,0 +1,198 @@
+package quiz
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// historyRecords reads testdata/history.jsonl, which has three runs out of
+// order: a maths run, an older one from before records kept their maximum
+// points which ran out of time, and a capitals run which was stopped.
+func historyRecords(t *testing.T) []Record {
+	t.Helper()
+	records, err := OpenHistory(filepath.Join("testdata", "history.jsonl")).Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("read %d records, want 3", len(records))
+	}
+	return records
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRecord(t *testing.T) {
+	records := historyRecords(t)
+	tests := []struct {
+		bank     string
+		max      float64
+		accuracy float64
+		ended    EndReason
+		pasted   int
+	}{
+		{"maths.csv", 3, 2.0 / 3, "", 0},
+		// Without max_points every problem was worth a point
+		{"maths.csv", 2, 0.5, EndTimeUp, 0},
+		{"capitals.csv", 2, 0.75, EndStopped, 1},
+	}
+	for i, tt := range tests {
+		r := records[i]
+		if r.Bank != tt.bank {
+			t.Fatalf("record %d is of %q, want %q", i, r.Bank, tt.bank)
+		}
+		if r.Max() != tt.max || !closeTo(r.Accuracy(), tt.accuracy) {
+			t.Errorf("record %d scored %v of %v, want %v of %v", i, r.Accuracy(), r.Max(), tt.accuracy, tt.max)
+		}
+		if r.EndReason() != tt.ended {
+			t.Errorf("record %d ended %q, want %q", i, r.EndReason(), tt.ended)
+		}
+		if r.Pasted() != tt.pasted {
+			t.Errorf("record %d has %d pasted answers, want %d", i, r.Pasted(), tt.pasted)
+		}
+	}
+}
+
+func TestRecordAnswer(t *testing.T) {
+	tests := []struct {
+		a   RecordAnswer
+		key string
+	}{
+		{RecordAnswer{Question: "5+5"}, "5+5"},
+		{RecordAnswer{ID: "pi", Question: "pi to 2dp"}, "pi"},
+		{RecordAnswer{Question: "Paris", Expected: "capital of France", Reversed: true}, "reverse:capital of France"},
+		{RecordAnswer{ID: "fr", Question: "Paris", Expected: "capital of France", Reversed: true}, "reverse:fr"},
+	}
+	for _, tt := range tests {
+		if key := tt.a.Key(); key != tt.key {
+			t.Errorf("Key() of %+v = %q, want %q", tt.a, key, tt.key)
+		}
+	}
+
+	a := RecordAnswer{LatencyMS: 1500, KeystrokesMS: []int64{120, 80}}
+	if a.Latency() != 1500*time.Millisecond {
+		t.Errorf("Latency() = %v, want 1.5s", a.Latency())
+	}
+	if want := []time.Duration{120 * time.Millisecond, 80 * time.Millisecond}; !reflect.DeepEqual(a.Keystrokes(), want) {
+		t.Errorf("Keystrokes() = %v, want %v", a.Keystrokes(), want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(historyRecords(t))
+
+	var banks []string
+	for _, r := range s.Runs {
+		banks = append(banks, r.Time.Format("2006-01-02")+" "+r.Bank)
+	}
+	if want := []string{"2026-01-01 maths.csv", "2026-01-02 capitals.csv", "2026-01-03 maths.csv"}; !reflect.DeepEqual(banks, want) {
+		t.Errorf("runs = %q, want them oldest first: %q", banks, want)
+	}
+
+	// Problems are kept apart by bank, and by direction when reversed
+	want := []ProblemStats{
+		{Bank: "maths.csv", Key: "5+5", Question: "5+5", Asked: 2, Correct: 2, TotalLatency: 3 * time.Second},
+		{Bank: "maths.csv", Key: "7+3", Question: "7+3", Asked: 2, Missed: 2, TotalLatency: 7 * time.Second},
+		{Bank: "capitals.csv", Key: "capital of France", Question: "capital of France", Asked: 1, Correct: 1, TotalLatency: time.Second},
+		{Bank: "capitals.csv", Key: "reverse:capital of France", Question: "Paris", Asked: 1, Missed: 1, TotalLatency: 5 * time.Second},
+		{Bank: "maths.csv", Key: "pi", Question: "pi to 2dp", Asked: 1, Correct: 1, TotalLatency: 6 * time.Second},
+	}
+	if !reflect.DeepEqual(s.Problems, want) {
+		t.Errorf("Problems =\n%+v\nwant\n%+v", s.Problems, want)
+	}
+	if p := s.Problems[0]; p.Accuracy() != 1 || p.AverageLatency() != 1500*time.Millisecond {
+		t.Errorf("%q was right %v of the time in %v on average, want 1 in 1.5s", p.Key, p.Accuracy(), p.AverageLatency())
+	}
+
+	keys := func(problems []ProblemStats) []string {
+		var keys []string
+		for _, p := range problems {
+			keys = append(keys, p.Key)
+		}
+		return keys
+	}
+	if got, want := keys(s.MostMissed(2)), []string{"7+3", "reverse:capital of France"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MostMissed(2) = %q, want %q", got, want)
+	}
+	if got, want := keys(s.MostMissed(10)), []string{"7+3", "reverse:capital of France"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MostMissed(10) = %q, want only the missed problems %q", got, want)
+	}
+	if got, want := keys(s.Slowest(2)), []string{"pi", "reverse:capital of France"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Slowest(2) = %q, want %q", got, want)
+	}
+
+	// 4.5 of the 7 points over every run, with the oldest run at half and
+	// the newer two at 3/4 and 2/3
+	if !closeTo(s.Accuracy(), 4.5/7) {
+		t.Errorf("Accuracy() = %v, want %v", s.Accuracy(), 4.5/7)
+	}
+	if older, newer := s.Trend(); !closeTo(older, 0.5) || !closeTo(newer, (0.75+2.0/3)/2) {
+		t.Errorf("Trend() = %v, %v, want %v, %v", older, newer, 0.5, (0.75+2.0/3)/2)
+	}
+	if older, newer := Summarize(s.Runs[:1]).Trend(); older != 0 || newer != 0 {
+		t.Errorf("Trend() of one run = %v, %v, want 0, 0", older, newer)
+	}
+}
+
+func TestHistoryAdd(t *testing.T) {
+	h := OpenHistory(filepath.Join(t.TempDir(), "quiz", "history.jsonl"))
+	if records, err := h.Records(); err != nil || records != nil {
+		t.Fatalf("Records() of a new history = %v, %v, want nothing", records, err)
+	}
+
+	want := historyRecords(t)
+	for _, r := range want {
+		if err := h.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := h.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Records() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// A broken line is reported with where it is
+	if err := os.WriteFile(h.Path(), []byte("{}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Records(); err == nil || !strings.Contains(err.Error(), "history.jsonl:2:") {
+		t.Errorf("Records() error = %v, want one for line 2", err)
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	result := Result{
+		Total:     3,
+		MaxPoints: 3,
+		Ended:     EndStopped,
+		Answers: []Answer{
+			{
+				Problem: Problem{ID: "add", Question: "5+5", Answer: "10"}, Given: "10", Correct: true, Credit: 1, Points: 0.75,
+				HintsUsed: 1, Latency: 2500 * time.Millisecond, Keystrokes: []time.Duration{100 * time.Millisecond},
+			},
+			{Problem: Problem{Question: "capital of France", Answer: "Paris"}, Latency: 10 * time.Second, TimedOut: true},
+		},
+	}
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	rec := NewRecord(result, "maths.csv", "ada", at)
+	want := Record{
+		Time: at, Bank: "maths.csv", User: "ada", Total: 3, Points: 0.75, MaxPoints: 3, Ended: EndStopped,
+		Answers: []RecordAnswer{
+			{ID: "add", Question: "5+5", Expected: "10", Given: "10", Correct: true, Credit: 1, Points: 0.75, Hints: 1, LatencyMS: 2500, KeystrokesMS: []int64{100}},
+			{Question: "capital of France", Expected: "Paris", LatencyMS: 10000, TimedOut: true},
+		},
+	}
+	if !reflect.DeepEqual(rec, want) {
+		t.Errorf("NewRecord() =\n%+v\nwant\n%+v", rec, want)
+	}
+}
@@ -0,0 +1,58 @@
+package quiz
+
+import "math/rand"
+
+// Strategy chooses which problem Run asks next.
+type Strategy interface {
+	// Len returns how many problems will be asked.
+	Len() int
+	// Next returns the problem to ask after the given answers, which are
+	// every answer so far with the most recent last. It returns false
+	// once there are no more problems to ask.
+	Next(answers []Answer) (Problem, bool)
+}
+
+// orderedStrategy is a Strategy which knows every problem it will ask up
+// front. Only quizzes using one can be saved with a checkpoint.
+type orderedStrategy interface {
+	Strategy
+	Order() []Problem
+}
+
+// WithStrategy sets how Run picks the next problem from the problems given
+// to New. By default they are asked in the order they were given.
+func WithStrategy(s Strategy) Option {
+	return func(q *Quiz) {
+		q.strategy = s
+	}
+}
+
+// InOrder asks the problems in the order they are given.
+func InOrder(problems []Problem) Strategy {
+	return inOrder(problems)
+}
+
+type inOrder []Problem
+
+func (s inOrder) Len() int {
+	return len(s)
+}
+
+func (s inOrder) Next(answers []Answer) (Problem, bool) {
+	if len(answers) >= len(s) {
+		return Problem{}, false
+	}
+	return s[len(answers)], true
+}
+
+func (s inOrder) Order() []Problem {
+	return s
+}
+
+// Shuffled asks the problems in a random order. The problems passed in
+// are left as they are.
+func Shuffled(problems []Problem, rnd *rand.Rand) Strategy {
+	shuffled := append([]Problem(nil), problems...)
+	Shuffle(shuffled, rnd)
+	return inOrder(shuffled)
+}
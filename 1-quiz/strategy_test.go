@@ -0,0 +1,92 @@
+package quiz
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShuffled(t *testing.T) {
+	problems := []Problem{
+		{Question: "1+1", Answer: "2"}, {Question: "2+2", Answer: "4"}, {Question: "3+3", Answer: "6"},
+		{Question: "4+4", Answer: "8"}, {Question: "5+5", Answer: "10"}, {Question: "6+6", Answer: "12"},
+	}
+	original := append([]Problem(nil), problems...)
+
+	want := append([]Problem(nil), problems...)
+	Shuffle(want, rand.New(rand.NewSource(3)))
+	if reflect.DeepEqual(want, problems) {
+		t.Fatal("the seed leaves the problems in order, pick another")
+	}
+
+	q := New(problems, WithStrategy(Shuffled(problems, rand.New(rand.NewSource(3)))), WithTimeLimit(0))
+	if !reflect.DeepEqual(problems, original) {
+		t.Errorf("Shuffled() changed the problems passed in")
+	}
+	if got := q.Problems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Problems() = %v, want %v", got, want)
+	}
+
+	// Run asks them in the shuffled order, and so do sessions
+	var answers strings.Builder
+	answers.WriteString("\n")
+	for _, p := range want {
+		answers.WriteString(p.Answer + "\n")
+	}
+	result, err := q.Run(strings.NewReader(answers.String()), &strings.Builder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Score() != len(want) {
+		t.Errorf("Run() got %d right, want %d", result.Score(), len(want))
+	}
+
+	s := q.Start()
+	for i, p := range want {
+		question, ok := s.Next()
+		if !ok || question.Question != p.Question {
+			t.Fatalf("session problem %d = %q, want %q", i+1, question.Question, p.Question)
+		}
+		if _, err := s.Submit(i+1, p.Answer, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSessionStrategy(t *testing.T) {
+	// Two quick right answers move the adaptive strategy up a level, and a
+	// session asks what it picks rather than the problems in bank order
+	var problems []Problem
+	for d := 1; d <= 2; d++ {
+		for i := 1; i <= 3; i++ {
+			problems = append(problems, Problem{Question: fmt.Sprintf("d%d-%d", d, i), Answer: "x", Difficulty: d})
+		}
+	}
+	clock := newFakeClock()
+	s := New(problems, WithClock(clock), WithTimeLimit(0),
+		WithStrategy(NewAdaptive(problems, 3, rand.New(rand.NewSource(1))))).Start()
+
+	for i, difficulty := range []int{1, 1, 2} {
+		question, ok := s.Next()
+		if !ok {
+			t.Fatalf("the session ended after %d problems, want 3", i)
+		}
+		if question.Number != i+1 || question.Total != 3 {
+			t.Errorf("question is %d/%d, want %d/3", question.Number, question.Total, i+1)
+		}
+		if !strings.HasPrefix(question.Question, fmt.Sprintf("d%d-", difficulty)) {
+			t.Errorf("problem %d is %q, want difficulty %d", i+1, question.Question, difficulty)
+		}
+		if _, err := s.Submit(i+1, "x", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !s.Done() {
+		t.Error("the session carried on after the strategy's 3 problems")
+	}
+	if result := s.Result(); result.Total != 3 || result.Score() != 3 {
+		t.Errorf("result is %d/%d, want 3/3", result.Score(), result.Total)
+	}
+}